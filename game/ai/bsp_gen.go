@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"game/model"
+	"math/rand"
+)
+
+// BSPGenerator partitions the world recursively into leaves and places one
+// dungeon inside each leaf.
+//
+// Leaves are split until there are as many as rooms are required, or until
+// they're about the size of the largest dungeon.
+type BSPGenerator struct{}
+
+func (BSPGenerator) Generate(rng *rand.Rand, config DungeonConfig) ([]*model.Dungeon, error) {
+	placement := newPlacement(config)
+	unit := getMinSize()
+	inset := (config.Margin+1)/2 + 1
+	minLeaf := getDungeonSize(config.MinFactor)
+	maxLeaf := getDungeonSize(config.MaxFactor)
+	minWidth := minLeaf.Width() + 2*inset
+	minHeight := minLeaf.Height() + 2*inset
+	leaves := []bspLeaf{{
+		left:   config.Margin - inset,
+		top:    config.Margin - inset,
+		right:  config.Width - config.Margin + inset,
+		bottom: config.Height - config.Margin + inset,
+	}}
+	canSplit := func(leaf bspLeaf) bool {
+		return leaf.width() >= 2*minWidth || leaf.height() >= 2*minHeight
+	}
+	isLarge := func(leaf bspLeaf) bool {
+		return leaf.width() > maxLeaf.Width()+2*inset || leaf.height() > maxLeaf.Height()+2*inset
+	}
+
+	for i := 0; i < config.Attempts; i++ {
+		if config.Rooms > 0 && len(leaves) >= config.Rooms {
+			break
+		}
+		next := -1
+
+		for j, leaf := range leaves {
+			if !canSplit(leaf) || (config.Rooms == 0 && !isLarge(leaf)) {
+				continue
+			}
+			if next == -1 || leaf.area() > leaves[next].area() {
+				next = j
+			}
+		}
+		if next == -1 {
+			break
+		}
+		a, b := leaves[next].split(rng, minWidth, minHeight)
+		leaves[next] = a
+		leaves = append(leaves, b)
+	}
+
+	for _, leaf := range leaves {
+		if placement.isDone() {
+			break
+		}
+		factor := getRandomFactor(rng, config)
+		factor.Width = min(factor.Width, (leaf.width()-2*inset)/unit.Width())
+		factor.Height = min(factor.Height, (leaf.height()-2*inset)/unit.Width())
+
+		if factor.Width < config.MinFactor.Width || factor.Height < config.MinFactor.Height {
+			continue
+		}
+		size := getDungeonSize(factor)
+		x := leaf.left + inset + rng.Intn(leaf.width()-2*inset-size.Width()+1)
+		y := leaf.top + inset + rng.Intn(leaf.height()-2*inset-size.Height()+1)
+		rect := model.NewRect(x, y, x+size.Width(), y+size.Height())
+
+		if placement.fits(rect) {
+			placement.add(rect, factor)
+		}
+	}
+	return placement.dungeons, nil
+}
+
+type bspLeaf struct {
+	left   int
+	top    int
+	right  int
+	bottom int
+}
+
+func (l bspLeaf) width() int {
+	return l.right - l.left
+}
+
+func (l bspLeaf) height() int {
+	return l.bottom - l.top
+}
+
+func (l bspLeaf) area() int {
+	return l.width() * l.height()
+}
+
+// split divides the leaf across its longest side that can be split at a
+// random point, so both halves are at least of the given size.
+func (l bspLeaf) split(rng *rand.Rand, minWidth int, minHeight int) (bspLeaf, bspLeaf) {
+	vertical := l.width() >= 2*minWidth
+
+	if l.height() >= 2*minHeight && (!vertical || l.height() > l.width()) {
+		vertical = false
+	}
+	a, b := l, l
+
+	if vertical {
+		x := l.left + minWidth + rng.Intn(l.width()-2*minWidth+1)
+		a.right = x
+		b.left = x
+	} else {
+		y := l.top + minHeight + rng.Intn(l.height()-2*minHeight+1)
+		a.bottom = y
+		b.top = y
+	}
+	return a, b
+}
@@ -0,0 +1,444 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"fmt"
+	"game/model"
+	"math"
+	"math/rand"
+)
+
+const (
+	UniformPlacement  = "uniform"
+	SizePlacement     = "size"
+	LeavesPlacement   = "leaves"
+	FarPlacement      = "far"
+	TreasurePlacement = "treasure"
+	CorridorPlacement = "corridor"
+)
+
+// leafWeight is how many times more likely a dead end dungeon is to get a
+// diamond than any other dungeon with the leaves placement.
+const leafWeight = 4
+
+// DefaultDiamondConfig drops one diamond at a random point of each dungeon,
+// most of them common.
+var DefaultDiamondConfig = DiamondConfig{
+	Placement: UniformPlacement,
+	Count:     0,
+	Clusters:  1,
+	Tolerance: 0,
+	Attempts:  1000,
+	Kinds:     []float64{0.75, 0.1, 0.1, 0.05},
+}
+
+// DiamondConfig defines how the diamonds of a match are placed.
+//
+// Placement is the name of the policy that picks where each diamond goes:
+// uniform picks any dungeon alike, size weights dungeons by their
+// area, leaves prefers dead ends, far weights dungeons by their distance to
+// the closest spawn, treasure packs every diamond in a few rooms and corridor
+// drops them along the paths.
+//
+// Count is the number of diamonds. If it's zero there's one diamond per
+// dungeon, and the uniform placement drops exactly one in each. Clusters is
+// the number of treasure rooms of the treasure placement.
+//
+// Tolerance bounds how unfair the placement is to any spawn. A diamond is
+// owned by the spawn it's closest to, following the paths, and no spawn can
+// own more than its even share of the diamonds plus this fraction of it, so
+// zero disables the constraint. Attempts is the budget of placement tries to
+// meet the constraint.
+//
+// Kinds are the odds of each kind of diamond, indexed by model.DiamondKind.
+// Every diamond is common if there are none.
+type DiamondConfig struct {
+	Placement string
+	Count     int
+	Clusters  int
+	Tolerance float64
+	Attempts  int
+	Kinds     []float64
+}
+
+// Validate returns an error if the diamonds can't be placed with this config.
+func (c *DiamondConfig) Validate() error {
+	switch c.Placement {
+	case "", UniformPlacement, SizePlacement, LeavesPlacement, FarPlacement, TreasurePlacement, CorridorPlacement:
+	default:
+		return fmt.Errorf("%w: unknown diamond placement %q", ErrUnsatisfiable, c.Placement)
+	}
+	if c.Count < 0 || c.Tolerance < 0 || c.Attempts <= 0 {
+		return fmt.Errorf("%w: count, tolerance and attempts can't be negative", ErrUnsatisfiable)
+	}
+	if c.Placement == TreasurePlacement && c.Clusters < 1 {
+		return fmt.Errorf("%w: treasure placement needs at least one cluster", ErrUnsatisfiable)
+	}
+	if len(c.Kinds) > model.DiamondKinds {
+		return fmt.Errorf("%w: there are only %d kinds of diamonds", ErrUnsatisfiable, model.DiamondKinds)
+	}
+	for _, odds := range c.Kinds {
+		if odds < 0 {
+			return fmt.Errorf("%w: diamond kind odds can't be negative", ErrUnsatisfiable)
+		}
+	}
+	return nil
+}
+
+// GenerateDiamonds places the diamonds of a match with the policy of the
+// config, keeping them fair to the given spawns, which are dungeon indices.
+func GenerateDiamonds(
+	rng *rand.Rand,
+	dungeons []*model.Dungeon,
+	paths []*model.Path,
+	spawns []int,
+	config DiamondConfig,
+) ([]*model.Diamond, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	// One diamond per dungeon, unless it has to be fair to the spawns
+	if config.Placement == "" || config.Placement == UniformPlacement {
+		if config.Count == 0 && config.Tolerance == 0 {
+			diamonds := generateDiamonds(rng, dungeons)
+			setDiamondKinds(rng, diamonds, config.Kinds)
+			return diamonds, nil
+		}
+	}
+	placer := newDiamondPlacer(dungeons, paths, spawns, config)
+	count := config.Count
+
+	if len(placer.weights) == 0 {
+		return nil, fmt.Errorf("%w: nowhere to place diamonds", ErrUnsatisfiable)
+	}
+	if count == 0 {
+		count = len(dungeons)
+	}
+	placer.pickClusters(rng)
+
+	for attempts := 0; len(placer.diamonds) < count; attempts++ {
+		if attempts >= config.Attempts {
+			return nil, fmt.Errorf(
+				"%w: placed %d of %d fair diamonds",
+				ErrUnsatisfiable,
+				len(placer.diamonds),
+				count,
+			)
+		}
+		placer.tryPlace(rng, count)
+	}
+	setDiamondKinds(rng, placer.diamonds, config.Kinds)
+	return placer.diamonds, nil
+}
+
+// setDiamondKinds turns each diamond into a random kind with the given odds.
+func setDiamondKinds(rng *rand.Rand, diamonds []*model.Diamond, kinds []float64) {
+	if len(kinds) == 0 {
+		return
+	}
+	for _, diamond := range diamonds {
+		rect := diamond.Rect()
+		kind := model.DiamondKind(pickWeighted(rng, kinds))
+		*diamond = model.NewDiamondOfKind(model.NewPoint(rect.Left(), rect.Top()), kind)
+	}
+}
+
+// diamondPlacer keeps the diamonds placed so far, and how many of them each
+// spawn owns.
+type diamondPlacer struct {
+	config    DiamondConfig
+	dungeons  []*model.Dungeon
+	paths     []*model.Path
+	graph     *matchGraph
+	spawns    []int
+	distances [][]int
+	weights   []float64
+	corridors [][]corridorSpan
+	owned     []int
+	diamonds  []*model.Diamond
+}
+
+// tryPlace picks a spot for the next diamond and places it there unless it
+// breaks the fairness constraint.
+func (p *diamondPlacer) tryPlace(rng *rand.Rand, count int) {
+	var point model.Point
+	var near []int
+
+	if p.config.Placement == CorridorPlacement {
+		point, near = p.pickCorridorPoint(rng)
+	} else {
+		i := pickWeighted(rng, p.weights)
+		point = p.dungeons[i].RandomPoint(rng, model.DiamondWidthPx)
+		near = []int{i}
+	}
+	owner := p.getOwner(near)
+
+	if owner != -1 && !p.isFair(owner, count) {
+		return
+	}
+	if owner != -1 {
+		p.owned[owner]++
+	}
+	diamond := model.NewDiamond(point)
+	p.diamonds = append(p.diamonds, &diamond)
+}
+
+// pickCorridorPoint returns a point in the middle of a random path, out of
+// the dungeons, picked with a probability proportional to the length of its
+// corridor, so that the diamond is centered on the corridor. It also returns
+// the dungeons the path connects.
+func (p *diamondPlacer) pickCorridorPoint(rng *rand.Rand) (model.Point, []int) {
+	i := pickWeighted(rng, p.weights)
+	ends := p.graph.ends[i]
+
+	// Every path runs within dungeons, so the diamond goes to a room instead
+	if p.weights[i] == 0 {
+		room := rng.Intn(len(p.dungeons))
+		return p.dungeons[room].RandomPoint(rng, model.DiamondWidthPx), []int{room}
+	}
+	offset := rng.Intn(int(p.weights[i]))
+
+	for _, span := range p.corridors[i] {
+		if offset >= span.end-span.start {
+			offset -= span.end - span.start
+			continue
+		}
+		p1 := span.line.P1()
+		p2 := span.line.P2()
+		x := p1.X() + sign(p2.X()-p1.X())*(span.start+offset)
+		y := p1.Y() + sign(p2.Y()-p1.Y())*(span.start+offset)
+		point := model.NewPoint(x-model.DiamondWidthPx/2, y-model.DiamondHeightPx/2)
+		return point, ends[:]
+	}
+	panic("Offset must lie within the corridor")
+}
+
+// corridorSpan is a part of a path line, from start to end along it.
+type corridorSpan struct {
+	line  model.Line
+	start int
+	end   int
+}
+
+// getCorridorSpans returns the parts of the path lines where a diamond
+// centered on them is out of every dungeon.
+func getCorridorSpans(path *model.Path, dungeons []*model.Dungeon) []corridorSpan {
+	var spans []corridorSpan
+	margin := max(model.DiamondWidthPx, model.DiamondHeightPx)/2 + 1
+
+	for _, line := range path.Lines() {
+		if line.IsDegenerate() {
+			continue
+		}
+		p1 := line.P1()
+		p2 := line.P2()
+		parts := [][2]int{{0, line.Length()}}
+
+		for _, dungeon := range dungeons {
+			rect := dungeon.Rect()
+			var from, to, origin, direction int
+
+			if line.IsHorizontal() {
+				if p1.Y() < rect.Top()-margin || p1.Y() > rect.Bottom()+margin {
+					continue
+				}
+				from, to = rect.Left()-margin, rect.Right()+margin
+				origin, direction = p1.X(), sign(p2.X()-p1.X())
+			} else {
+				if p1.X() < rect.Left()-margin || p1.X() > rect.Right()+margin {
+					continue
+				}
+				from, to = rect.Top()-margin, rect.Bottom()+margin
+				origin, direction = p1.Y(), sign(p2.Y()-p1.Y())
+			}
+			a := (from - origin) * direction
+			b := (to - origin) * direction
+			parts = cutRange(parts, min(a, b), max(a, b))
+		}
+		for _, part := range parts {
+			spans = append(spans, corridorSpan{line, part[0], part[1]})
+		}
+	}
+	return spans
+}
+
+// cutRange removes the range between from and to out of the given parts.
+func cutRange(parts [][2]int, from int, to int) [][2]int {
+	var cut [][2]int
+
+	for _, part := range parts {
+		if part[0] < from {
+			cut = append(cut, [2]int{part[0], min(part[1], from)})
+		}
+		if part[1] > to {
+			cut = append(cut, [2]int{max(part[0], to), part[1]})
+		}
+	}
+	return cut
+}
+
+// pickClusters sets the weights of the treasure placement, so only the
+// treasure rooms, picked weighted by size, can get diamonds.
+func (p *diamondPlacer) pickClusters(rng *rand.Rand) {
+	if p.config.Placement != TreasurePlacement {
+		return
+	}
+	sizes := getSizeWeights(p.dungeons)
+	clusters := make([]float64, len(p.dungeons))
+
+	for i := 0; i < min(p.config.Clusters, len(p.dungeons)); i++ {
+		room := pickWeighted(rng, sizes)
+		clusters[room] = sizes[room]
+		sizes[room] = 0
+	}
+	p.weights = clusters
+}
+
+// getOwner returns the spawn closest to any of the given dungeons, or -1 if
+// there are no spawns or none of them can reach the dungeons.
+func (p *diamondPlacer) getOwner(near []int) int {
+	owner := -1
+	best := math.MaxInt32
+
+	for s := range p.spawns {
+		for _, i := range near {
+			if i != -1 && p.distances[s][i] < best {
+				owner = s
+				best = p.distances[s][i]
+			}
+		}
+	}
+	return owner
+}
+
+// isFair tells whether the spawn can own one more diamond out of count.
+func (p *diamondPlacer) isFair(spawn int, count int) bool {
+	if p.config.Tolerance == 0 || len(p.spawns) < 2 {
+		return true
+	}
+	share := float64(count) / float64(len(p.spawns))
+	quota := int(math.Ceil(share * (1 + p.config.Tolerance)))
+	return p.owned[spawn] < quota
+}
+
+func newDiamondPlacer(
+	dungeons []*model.Dungeon,
+	paths []*model.Path,
+	spawns []int,
+	config DiamondConfig,
+) *diamondPlacer {
+	graph := newMatchGraph(&model.Match{Dungeons: dungeons, Paths: paths})
+	placer := &diamondPlacer{
+		config:    config,
+		dungeons:  dungeons,
+		paths:     paths,
+		graph:     graph,
+		spawns:    spawns,
+		distances: make([][]int, len(spawns)),
+		owned:     make([]int, len(spawns)),
+	}
+
+	for s, spawn := range spawns {
+		placer.distances[s] = graph.distancesFrom(spawn)
+	}
+	switch config.Placement {
+	case SizePlacement, TreasurePlacement:
+		placer.weights = getSizeWeights(dungeons)
+	case LeavesPlacement:
+		placer.weights = make([]float64, len(dungeons))
+
+		for i, edges := range graph.adjacency {
+			placer.weights[i] = 1
+
+			if len(edges) == 1 {
+				placer.weights[i] = leafWeight
+			}
+		}
+	case FarPlacement:
+		placer.weights = make([]float64, len(dungeons))
+
+		for i := range dungeons {
+			nearest := math.MaxInt32
+
+			for s := range spawns {
+				nearest = min(nearest, placer.distances[s][i])
+			}
+			if nearest != math.MaxInt32 {
+				placer.weights[i] = float64(nearest)
+			}
+		}
+	case CorridorPlacement:
+		placer.weights = make([]float64, len(paths))
+		placer.corridors = make([][]corridorSpan, len(paths))
+
+		for i, path := range paths {
+			placer.corridors[i] = getCorridorSpans(path, dungeons)
+
+			for _, span := range placer.corridors[i] {
+				placer.weights[i] += float64(span.end - span.start)
+			}
+		}
+	default:
+		placer.weights = make([]float64, len(dungeons))
+
+		for i := range placer.weights {
+			placer.weights[i] = 1
+		}
+	}
+	return placer
+}
+
+func getSizeWeights(dungeons []*model.Dungeon) []float64 {
+	weights := make([]float64, len(dungeons))
+
+	for i, dungeon := range dungeons {
+		weights[i] = float64(dungeon.Width() * dungeon.Height())
+	}
+	return weights
+}
+
+// pickWeighted returns a random index with a probability proportional to its
+// weight, or a uniform one if every weight is zero.
+func pickWeighted(rng *rand.Rand, weights []float64) int {
+	total := 0.0
+
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return rng.Intn(len(weights))
+	}
+	target := rng.Float64() * total
+
+	for i, weight := range weights {
+		if target < weight {
+			return i
+		}
+		target -= weight
+	}
+	return len(weights) - 1
+}
+
+func generateDiamonds(rng *rand.Rand, dungeons []*model.Dungeon) []*model.Diamond {
+	var diamonds []*model.Diamond
+
+	for _, dungeon := range dungeons {
+		point := dungeon.RandomPoint(rng, model.DiamondWidthPx)
+		diamond := model.NewDiamond(point)
+		diamonds = append(diamonds, &diamond)
+	}
+	return diamonds
+}
+
+func sign(a int) int {
+	if a < 0 {
+		return -1
+	}
+	if a > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"fmt"
+	"game/model"
+	"math/rand"
+)
+
+// hazardAttempts is the number of tries to place each hazard, or the target
+// of a teleport pad, before giving up on it.
+const hazardAttempts = 20
+
+// DefaultHazardConfig places up to two hazards of any kind alike in each
+// dungeon big enough.
+var DefaultHazardConfig = HazardConfig{
+	Max:   2,
+	Kinds: []float64{1, 1, 1, 1},
+}
+
+// HazardConfig defines the hazards placed in the dungeons of a match.
+//
+// Each dungeon gets a random number of hazards up to Max, so zero disables
+// them, except for the ones too small to walk around a hazard. Kinds are the
+// odds of each kind of hazard, indexed by model.HazardKind.
+type HazardConfig struct {
+	Max   int
+	Kinds []float64
+}
+
+// Validate returns an error if hazards can't be placed with this config.
+func (c *HazardConfig) Validate() error {
+	if c.Max < 0 {
+		return fmt.Errorf("%w: max hazards can't be negative", ErrUnsatisfiable)
+	}
+	if len(c.Kinds) == 0 || len(c.Kinds) > model.HazardKinds {
+		return fmt.Errorf("%w: there must be odds for 1 to %d kinds of hazards", ErrUnsatisfiable, model.HazardKinds)
+	}
+	for _, odds := range c.Kinds {
+		if odds < 0 {
+			return fmt.Errorf("%w: hazard kind odds can't be negative", ErrUnsatisfiable)
+		}
+	}
+	return nil
+}
+
+// hazardPlacement is a hazard about to be added to the dungeon at index.
+type hazardPlacement struct {
+	dungeon int
+	point   model.Point
+	kind    model.HazardKind
+}
+
+// PlaceHazards adds the hazards of the config to the dungeons. Hazards keep
+// off the center of each dungeon, where runners spawn, off the diamonds and
+// off each other. Teleport pads send runners to a free spot of another
+// dungeon.
+func PlaceHazards(rng *rand.Rand, dungeons []*model.Dungeon, diamonds []*model.Diamond, config HazardConfig) {
+	if config.Max == 0 {
+		return
+	}
+	taken := make([][]model.Rect, len(dungeons))
+	var placements []hazardPlacement
+
+	for i, dungeon := range dungeons {
+		if !canHoldHazards(dungeon) {
+			continue
+		}
+		runner := model.NewRunner()
+		runner.SetDungeon(dungeon)
+		taken[i] = append(taken[i], runner.Rect)
+
+		for _, diamond := range diamonds {
+			if rect := diamond.Rect(); dungeon.Intersects(&rect) {
+				taken[i] = append(taken[i], rect)
+			}
+		}
+		count := rng.Intn(config.Max + 1)
+
+		for j := 0; j < count; j++ {
+			point, ok := findFreePoint(rng, dungeon, taken[i], model.HazardSizePx)
+
+			if !ok {
+				break
+			}
+			kind := model.HazardKind(pickWeighted(rng, config.Kinds))
+			taken[i] = append(taken[i], model.NewRect(
+				point.X(),
+				point.Y(),
+				point.X()+model.HazardSizePx,
+				point.Y()+model.HazardSizePx,
+			))
+			placements = append(placements, hazardPlacement{i, point, kind})
+		}
+	}
+	for _, placement := range placements {
+		target := model.Point{}
+
+		if placement.kind == model.HazardTeleport {
+			target = getTeleportTarget(rng, dungeons, taken, placement.dungeon)
+		}
+		hazard := model.NewHazard(placement.point, placement.kind, target)
+		dungeons[placement.dungeon].AddHazard(hazard)
+	}
+}
+
+// getTeleportTarget returns where a runner lands from a teleport pad of the
+// given dungeon, a free spot of another dungeon or its center if there's no
+// free spot.
+func getTeleportTarget(rng *rand.Rand, dungeons []*model.Dungeon, taken [][]model.Rect, from int) model.Point {
+	to := from
+
+	if len(dungeons) > 1 {
+		to = rng.Intn(len(dungeons) - 1)
+
+		if to >= from {
+			to++
+		}
+	}
+	runner := model.NewRunner()
+	runner.SetDungeon(dungeons[to])
+
+	// The center is where runners spawn, so it's free of hazards
+	center := model.NewPoint(runner.Rect.Left(), runner.Rect.Top())
+
+	if !canHoldHazards(dungeons[to]) {
+		return center
+	}
+	if point, ok := findFreePoint(rng, dungeons[to], taken[to], runner.Rect.Width()); ok {
+		return point
+	}
+	return center
+}
+
+// findFreePoint returns a random point of the dungeon where a square of the
+// given size doesn't overlap the taken rects.
+func findFreePoint(rng *rand.Rand, dungeon *model.Dungeon, taken []model.Rect, size int) (model.Point, bool) {
+	for attempt := 0; attempt < hazardAttempts; attempt++ {
+		point := dungeon.RandomPoint(rng, size)
+		rect := model.NewRect(point.X(), point.Y(), point.X()+size, point.Y()+size)
+		free := true
+
+		for _, other := range taken {
+			if rect.Intersects(&other) {
+				free = false
+				break
+			}
+		}
+		if free {
+			return point, true
+		}
+	}
+	return model.Point{}, false
+}
+
+// canHoldHazards tells whether the floor of the dungeon is wide enough for a
+// runner to walk around a hazard.
+func canHoldHazards(dungeon *model.Dungeon) bool {
+	unit := model.GetDungeonHorizontalUnitSize()
+	wall := unit.Height()
+	return dungeon.Width()-2*wall > 2*model.HazardSizePx &&
+		dungeon.Height()-2*wall > 2*model.HazardSizePx
+}
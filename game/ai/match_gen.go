@@ -4,26 +4,76 @@
 
 package ai
 
-import "game/model"
+import (
+	"game/model"
+	"math/rand"
+)
 
-func NewRandomMatch(dimension model.Dimension) *model.Match {
-	dungeons := GenerateDungeons(dimension)
-	paths := GetPaths(dungeons)
-	diamonds := generateDiamonds(dungeons)
+// DefaultMatchConfig is the config matches are generated with unless another
+// one is given.
+var DefaultMatchConfig = MatchConfig{
+	Dungeons: DefaultDungeonConfig,
+	Paths:    DefaultPathConfig,
+	Diamonds: DefaultDiamondConfig,
+	Spawns:   DefaultSpawnConfig,
+	Pickups:  DefaultPickupConfig,
+	Hazards:  DefaultHazardConfig,
+}
+
+// MatchConfig defines how each part of a match is generated.
+type MatchConfig struct {
+	Dungeons DungeonConfig
+	Paths    PathConfig
+	Diamonds DiamondConfig
+	Spawns   SpawnConfig
+	Pickups  PickupConfig
+	Hazards  HazardConfig
+}
+
+// Validate returns an error if no match can be generated with this config.
+func (c *MatchConfig) Validate() error {
+	if err := c.Dungeons.Validate(); err != nil {
+		return err
+	}
+	if err := c.Diamonds.Validate(); err != nil {
+		return err
+	}
+	if err := c.Spawns.Validate(); err != nil {
+		return err
+	}
+	if err := c.Pickups.Validate(); err != nil {
+		return err
+	}
+	return c.Hazards.Validate()
+}
+
+// NewRandomMatch generates a match from the given seed. It's the same
+// generator the server runs, so the game can rebuild any match it receives.
+func NewRandomMatch(seed int64, config MatchConfig) (*model.Match, error) {
+	rng := rand.New(rand.NewSource(seed))
+	dungeons, err := GenerateDungeons(rng, config.Dungeons)
+
+	if err != nil {
+		return nil, err
+	}
+	paths := GetPaths(dungeons, config.Paths)
+	diamonds, err := GenerateDiamonds(rng, dungeons, paths, LayoutSpawns(dungeons, paths), config.Diamonds)
+
+	if err != nil {
+		return nil, err
+	}
+	PlaceHazards(rng, dungeons, diamonds, config.Hazards)
 	return &model.Match{
 		Dungeons: dungeons,
 		Paths:    paths,
 		Diamonds: diamonds,
-	}
+	}, nil
 }
 
-func generateDiamonds(dungeons []*model.Dungeon) []*model.Diamond {
-	var diamonds []*model.Diamond
-
-	for _, dungeon := range dungeons {
-		point := dungeon.RandomPoint(model.DiamondWidthPx)
-		diamond := model.NewDiamond(point)
-		diamonds = append(diamonds, &diamond)
-	}
-	return diamonds
+// VerifyMatch tells whether the dungeons and paths of the given match are the
+// ones generated from the given seed and config. Diamonds are not compared
+// since they're taken during the match.
+func VerifyMatch(match *model.Match, seed int64, config MatchConfig) bool {
+	expected, err := NewRandomMatch(seed, config)
+	return err == nil && expected.HasSameLayout(match)
 }
@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"fmt"
+	"game/model"
+	"math/rand"
+	"time"
+)
+
+// DefaultPickupConfig spawns a pickup of any kind alike every 8 seconds, up
+// to three at once.
+var DefaultPickupConfig = PickupConfig{
+	Interval: 8 * time.Second,
+	Max:      3,
+	Kinds:    []float64{1, 1, 1, 1},
+}
+
+// PickupConfig defines how pickups spawn during a match.
+//
+// A pickup spawns every interval at a random point of any dungeon but the
+// spawns, unless every dungeon is a spawn, as long as there are less than Max
+// pickups, so zero disables them. Kinds are the odds of each kind of pickup,
+// indexed by model.PickupKind.
+type PickupConfig struct {
+	Interval time.Duration
+	Max      int
+	Kinds    []float64
+}
+
+// Validate returns an error if pickups can't spawn with this config.
+func (c *PickupConfig) Validate() error {
+	if c.Max < 0 {
+		return fmt.Errorf("%w: max pickups can't be negative", ErrUnsatisfiable)
+	}
+	if c.Max > 0 && c.Interval <= 0 {
+		return fmt.Errorf("%w: pickup interval must be positive", ErrUnsatisfiable)
+	}
+	if len(c.Kinds) == 0 || len(c.Kinds) > model.PickupKinds {
+		return fmt.Errorf("%w: there must be odds for 1 to %d kinds of pickups", ErrUnsatisfiable, model.PickupKinds)
+	}
+	for _, odds := range c.Kinds {
+		if odds < 0 {
+			return fmt.Errorf("%w: pickup kind odds can't be negative", ErrUnsatisfiable)
+		}
+	}
+	return nil
+}
+
+// pickupSeedMask derives the seed of the pickups from the one of the match,
+// so they don't repeat the numbers of the match or of the respawns.
+const pickupSeedMask = 0x2545f4914f6cdd1d
+
+// PickupSpawner spawns the pickups of a match over time.
+type PickupSpawner struct {
+	rng        *rand.Rand
+	config     PickupConfig
+	candidates []int
+	last       time.Time
+}
+
+// Next returns the pickup due at the given time, or nil if it's not time to
+// spawn one yet or there are enough pickups.
+func (s *PickupSpawner) Next(match *model.Match, now time.Time) *model.Pickup {
+	if now.Sub(s.last) < s.config.Interval || len(match.Pickups) >= s.config.Max {
+		return nil
+	}
+	s.last = now
+	dungeon := match.Dungeons[s.candidates[s.rng.Intn(len(s.candidates))]]
+	kind := model.PickupKind(pickWeighted(s.rng, s.config.Kinds))
+	pickup := model.NewPickup(dungeon.RandomPoint(s.rng, model.PickupSizePx), kind)
+	return &pickup
+}
+
+// NewPickupSpawner returns the pickup spawner of the given match generated
+// from the given seed and config, starting at the given time.
+func NewPickupSpawner(seed int64, config MatchConfig, match *model.Match, start time.Time) *PickupSpawner {
+	return &PickupSpawner{
+		rng:        rand.New(rand.NewSource(seed ^ pickupSeedMask)),
+		config:     config.Pickups,
+		candidates: getPickupDungeons(match),
+		last:       start,
+	}
+}
+
+// getPickupDungeons returns the dungeons pickups spawn in, those that are not
+// spawns unless every dungeon is.
+func getPickupDungeons(match *model.Match) []int {
+	spawns := map[int]bool{}
+	var candidates []int
+
+	for _, spawn := range LayoutSpawns(match.Dungeons, match.Paths) {
+		spawns[spawn] = true
+	}
+	for i := range match.Dungeons {
+		if !spawns[i] {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		for i := range match.Dungeons {
+			candidates = append(candidates, i)
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"game/model"
+	"math"
+)
+
+// MaxSpawns is the number of spawn dungeons picked for each match, the
+// players beyond it share spawns.
+const MaxSpawns = 8
+
+// spawnSpreadSlack is the fraction of the best spread a dungeon can fall
+// short of and still be picked as a spawn if it's fairer to the diamonds.
+const spawnSpreadSlack = 0.25
+
+// SpawnDungeons returns the indices of up to n dungeons for players to spawn
+// at, spread as far from each other as the paths allow.
+//
+// Among the dungeons about as far from the spawns picked so far, the one whose
+// average walking distance to the diamonds is the closest to the average of
+// those spawns is picked, so no player starts much closer to the diamonds.
+func SpawnDungeons(match *model.Match, n int) []int {
+	count := len(match.Dungeons)
+	n = min(n, count)
+
+	if n <= 0 {
+		return nil
+	}
+	graph := newMatchGraph(match)
+	distances := make([][]int, count)
+	diamondDistances := make([]float64, count)
+	diamondDungeons := getDiamondDungeons(match, graph)
+
+	for i := range distances {
+		distances[i] = graph.distancesFrom(i)
+
+		for _, d := range diamondDungeons {
+			if distances[i][d] != math.MaxInt32 {
+				diamondDistances[i] += float64(distances[i][d])
+			}
+		}
+		if len(diamondDungeons) > 0 {
+			diamondDistances[i] /= float64(len(diamondDungeons))
+		}
+	}
+
+	// Start from the periphery, the farthest dungeon from the first one
+	first := 0
+
+	for i, distance := range distances[0] {
+		if distance != math.MaxInt32 && distance > distances[0][first] {
+			first = i
+		}
+	}
+	spawns := []int{first}
+	spread := make([]int, count)
+	target := diamondDistances[first]
+	copy(spread, distances[first])
+
+	for len(spawns) < n {
+		best := -1
+
+		for _, s := range spread {
+			if s != math.MaxInt32 && (best == -1 || s > best) {
+				best = s
+			}
+		}
+		next := -1
+
+		for i, s := range spread {
+			if s == 0 || s == math.MaxInt32 || float64(s) < float64(best)*(1-spawnSpreadSlack) {
+				continue
+			}
+			if next == -1 || math.Abs(diamondDistances[i]-target) < math.Abs(diamondDistances[next]-target) {
+				next = i
+			}
+		}
+		if next == -1 {
+			break
+		}
+		spawns = append(spawns, next)
+		target = (target*float64(len(spawns)-1) + diamondDistances[next]) / float64(len(spawns))
+
+		for i := range spread {
+			spread[i] = min(spread[i], distances[next][i])
+		}
+	}
+	return spawns
+}
+
+// getDiamondDungeons returns the index of the dungeon each diamond lies in,
+// or the closest one for the diamonds along the paths.
+func getDiamondDungeons(match *model.Match, graph *matchGraph) []int {
+	var dungeons []int
+
+	for _, diamond := range match.Diamonds {
+		rect := diamond.Rect()
+		center := rect.Center()
+		i := graph.dungeonAt(center)
+
+		if i == -1 {
+			for j, dungeon := range match.Dungeons {
+				if i == -1 || model.Distance(center, dungeon.Center()) <
+					model.Distance(center, match.Dungeons[i].Center()) {
+					i = j
+				}
+			}
+		}
+		dungeons = append(dungeons, i)
+	}
+	return dungeons
+}
+
+// LayoutSpawns returns the spawns of a layout before there are diamonds,
+// so the diamonds can be placed fairly to them. Players spawn at these, not
+// at the spawns found with the diamonds, which can differ.
+func LayoutSpawns(dungeons []*model.Dungeon, paths []*model.Path) []int {
+	return SpawnDungeons(&model.Match{Dungeons: dungeons, Paths: paths}, MaxSpawns)
+}
@@ -0,0 +1,265 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"container/heap"
+	"game/model"
+)
+
+const (
+	routeCellPx      = model.PathWidthPx / 2
+	routeMarginCells = 4
+	routeTurnCost    = 4 * routeCellPx
+)
+
+var routeDirections = [4][2]int{{-1, 0}, {0, -1}, {1, 0}, {0, 1}}
+
+// Router builds corridors between dungeons that don't cross any other
+// dungeon.
+//
+// It tries both L-shaped paths first, and if both collide it finds a route
+// with A* over a grid of corridor centers laid out around the dungeons.
+type Router struct {
+	dungeons []*model.Dungeon
+	x0       int
+	y0       int
+	cols     int
+	rows     int
+	blocks   [][]int
+}
+
+// Route returns the corridor from dungeon a to dungeon b, given by their
+// indices.
+func (r *Router) Route(a int, b int) *model.Path {
+	da := r.dungeons[a]
+	db := r.dungeons[b]
+	edge := Edge{A: a, B: b}
+
+	if path := da.GetPathFor(db); !crossesDungeons(path, r.dungeons, edge) {
+		return path
+	}
+	if path := db.GetPathFor(da); !crossesDungeons(path, r.dungeons, edge) {
+		return path
+	}
+	if path := r.findPath(a, b); path != nil {
+		return path
+	}
+	return da.GetPathFor(db)
+}
+
+// findPath returns the corridor found by A*, or nil if there's no such a
+// corridor.
+func (r *Router) findPath(a int, b int) *model.Path {
+	nodes := r.findNodes(a, b)
+
+	if len(nodes) < 2 {
+		return nil
+	}
+	path := model.NewPath(r.toPolyline(nodes))
+	return &path
+}
+
+func (r *Router) findNodes(a int, b int) []int {
+	start := r.nearestNode(r.dungeons[a].Center())
+	goal := r.nearestNode(r.dungeons[b].Center())
+	gx, gy := r.coordinates(goal)
+	blocked := func(node int) bool {
+		count := len(r.blocks[node])
+
+		for _, i := range r.blocks[node] {
+			if i == a || i == b {
+				count--
+			}
+		}
+		return count > 0
+	}
+	heuristic := func(node int) int {
+		x, y := r.coordinates(node)
+		return (abs(x-gx) + abs(y-gy)) * routeCellPx
+	}
+
+	// States are node and direction pairs, so turns can be penalized
+	costs := map[int]int{}
+	parents := map[int]int{}
+	open := &routeQueue{}
+
+	for dir := range routeDirections {
+		state := start*4 + dir
+		costs[state] = 0
+		parents[state] = -1
+		heap.Push(open, routeItem{state, heuristic(start)})
+	}
+	for open.Len() > 0 {
+		item := heap.Pop(open).(routeItem)
+		state := item.state
+		node, dir := state/4, state%4
+
+		if item.priority-heuristic(node) > costs[state] {
+			continue
+		}
+		if node == goal {
+			return r.backtrack(parents, state)
+		}
+		x, y := r.coordinates(node)
+
+		for next, delta := range routeDirections {
+			nx, ny := x+delta[0], y+delta[1]
+
+			if nx < 0 || ny < 0 || nx >= r.cols || ny >= r.rows {
+				continue
+			}
+			nextNode := ny*r.cols + nx
+
+			if blocked(nextNode) {
+				continue
+			}
+			cost := costs[state] + routeCellPx
+
+			if next != dir {
+				cost += routeTurnCost
+			}
+			nextState := nextNode*4 + next
+
+			if c, ok := costs[nextState]; ok && c <= cost {
+				continue
+			}
+			costs[nextState] = cost
+			parents[nextState] = state
+			heap.Push(open, routeItem{nextState, cost + heuristic(nextNode)})
+		}
+	}
+	return nil
+}
+
+func (r *Router) backtrack(parents map[int]int, state int) []int {
+	var nodes []int
+
+	for ; state != -1; state = parents[state] {
+		nodes = append([]int{state / 4}, nodes...)
+	}
+	return nodes
+}
+
+// toPolyline returns the corners of the given route of nodes.
+func (r *Router) toPolyline(nodes []int) []model.Point {
+	var points []model.Point
+
+	for i, node := range nodes {
+		if i > 0 && i < len(nodes)-1 {
+			px, py := r.coordinates(nodes[i-1])
+			nx, ny := r.coordinates(nodes[i+1])
+
+			if px == nx || py == ny {
+				continue
+			}
+		}
+		points = append(points, r.point(node))
+	}
+	return points
+}
+
+func (r *Router) nearestNode(point model.Point) int {
+	x := (point.X() - r.x0 + routeCellPx/2) / routeCellPx
+	y := (point.Y() - r.y0 + routeCellPx/2) / routeCellPx
+	return y*r.cols + x
+}
+
+func (r *Router) coordinates(node int) (int, int) {
+	return node % r.cols, node / r.cols
+}
+
+func (r *Router) point(node int) model.Point {
+	x, y := r.coordinates(node)
+	return model.NewPoint(r.x0+x*routeCellPx, r.y0+y*routeCellPx)
+}
+
+// NewRouter lays out the grid of corridor centers around the dungeons and
+// marks the ones a corridor can't go through.
+func NewRouter(dungeons []*model.Dungeon) *Router {
+	sw := model.PathWidthPx / 2
+	margin := routeMarginCells * routeCellPx
+	left, top, right, bottom := getBounds(dungeons)
+	x0 := max(sw, left-margin)
+	y0 := max(sw, top-margin)
+	router := &Router{
+		dungeons: dungeons,
+		x0:       x0,
+		y0:       y0,
+		cols:     (right+margin-x0)/routeCellPx + 1,
+		rows:     (bottom+margin-y0)/routeCellPx + 1,
+	}
+	router.blocks = make([][]int, router.cols*router.rows)
+
+	for i, dungeon := range dungeons {
+		l, t, r, b := getBounds(dungeons[i : i+1])
+		minX := max(0, (l-sw-x0)/routeCellPx)
+		minY := max(0, (t-sw-y0)/routeCellPx)
+		maxX := min(router.cols-1, (r+sw-x0)/routeCellPx+1)
+		maxY := min(router.rows-1, (b+sw-y0)/routeCellPx+1)
+
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				node := y*router.cols + x
+				p := router.point(node)
+				rect := model.NewRect(p.X()-sw, p.Y()-sw, p.X()+sw, p.Y()+sw)
+
+				if dungeon.Intersects(&rect) {
+					router.blocks[node] = append(router.blocks[node], i)
+				}
+			}
+		}
+	}
+	return router
+}
+
+// getBounds returns the left, top, right and bottom coordinates of the
+// smallest rectangle containing the dungeons.
+func getBounds(dungeons []*model.Dungeon) (int, int, int, int) {
+	if len(dungeons) == 0 {
+		return 0, 0, 0, 0
+	}
+	left, top := dungeons[0].Cx(), dungeons[0].Cy()
+	right, bottom := left, top
+
+	for _, dungeon := range dungeons {
+		left = min(left, dungeon.Cx()-dungeon.Width()/2)
+		top = min(top, dungeon.Cy()-dungeon.Height()/2)
+		right = max(right, dungeon.Cx()+dungeon.Width()/2)
+		bottom = max(bottom, dungeon.Cy()+dungeon.Height()/2)
+	}
+	return left, top, right, bottom
+}
+
+type routeItem struct {
+	state    int
+	priority int
+}
+
+type routeQueue []routeItem
+
+func (q routeQueue) Len() int {
+	return len(q)
+}
+
+func (q routeQueue) Less(i, j int) bool {
+	return q[i].priority < q[j].priority
+}
+
+func (q routeQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *routeQueue) Push(x interface{}) {
+	*q = append(*q, x.(routeItem))
+}
+
+func (q *routeQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
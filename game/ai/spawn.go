@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"fmt"
+	"game/model"
+	"math/rand"
+	"time"
+)
+
+const (
+	NoRespawn       = "none"
+	WaveRespawn     = "wave"
+	CooldownRespawn = "cooldown"
+)
+
+// spawnSeedMask derives the seed of the respawns from the one of the match,
+// so they don't repeat the numbers the match was generated with.
+const spawnSeedMask = 0x5851f42d4c957f2d
+
+// DefaultSpawnConfig refills the match with one diamond per dungeon every
+// 15 seconds.
+var DefaultSpawnConfig = SpawnConfig{
+	Mode:     WaveRespawn,
+	Interval: 15 * time.Second,
+	Size:     0,
+	Max:      0,
+}
+
+// SpawnConfig defines how diamonds respawn during a match.
+//
+// Mode is either none, wave to spawn a wave of diamonds every interval, or
+// cooldown to respawn each diamond an interval after it was taken. Size is
+// the number of diamonds of a wave and Max is the most diamonds there can be
+// at once, both are one per dungeon if they're zero.
+type SpawnConfig struct {
+	Mode     string
+	Interval time.Duration
+	Size     int
+	Max      int
+}
+
+// Validate returns an error if diamonds can't respawn with this config.
+func (c *SpawnConfig) Validate() error {
+	switch c.Mode {
+	case "", NoRespawn, WaveRespawn, CooldownRespawn:
+	default:
+		return fmt.Errorf("%w: unknown respawn mode %q", ErrUnsatisfiable, c.Mode)
+	}
+	if c.Size < 0 || c.Max < 0 {
+		return fmt.Errorf("%w: wave size and max diamonds can't be negative", ErrUnsatisfiable)
+	}
+	if c.Mode != "" && c.Mode != NoRespawn && c.Interval <= 0 {
+		return fmt.Errorf("%w: respawn interval must be positive", ErrUnsatisfiable)
+	}
+	return nil
+}
+
+// Spawner respawns diamonds within the layout of a match, placed with the
+// same policy as the first ones.
+type Spawner struct {
+	rng      *rand.Rand
+	config   SpawnConfig
+	diamonds DiamondConfig
+	spawns   []int
+	last     time.Time
+	taken    []time.Time
+}
+
+// Taken records that a diamond was taken at the given time, so it respawns
+// after the cooldown.
+func (s *Spawner) Taken(now time.Time) {
+	if s.config.Mode == CooldownRespawn {
+		s.taken = append(s.taken, now)
+	}
+}
+
+// Next returns the diamonds due at the given time, or none if it's not time
+// to spawn any yet.
+func (s *Spawner) Next(match *model.Match, now time.Time) ([]*model.Diamond, error) {
+	count := 0
+
+	switch s.config.Mode {
+	case WaveRespawn:
+		if now.Sub(s.last) < s.config.Interval {
+			return nil, nil
+		}
+		s.last = now
+		count = getOrDungeons(s.config.Size, match)
+	case CooldownRespawn:
+		for len(s.taken) > 0 && now.Sub(s.taken[0]) >= s.config.Interval {
+			s.taken = s.taken[1:]
+			count++
+		}
+	}
+	count = min(count, getOrDungeons(s.config.Max, match)-len(match.Diamonds))
+
+	if count <= 0 {
+		return nil, nil
+	}
+	config := s.diamonds
+	config.Count = count
+	return GenerateDiamonds(s.rng, match.Dungeons, match.Paths, s.spawns, config)
+}
+
+// NewSpawner returns the spawner of the given match generated from the given
+// seed and config, starting at the given time. The spawns of the players are
+// found once since the layout doesn't change.
+func NewSpawner(seed int64, config MatchConfig, match *model.Match, start time.Time) *Spawner {
+	return &Spawner{
+		rng:      rand.New(rand.NewSource(seed ^ spawnSeedMask)),
+		config:   config.Spawns,
+		diamonds: config.Diamonds,
+		spawns:   LayoutSpawns(match.Dungeons, match.Paths),
+		last:     start,
+	}
+}
+
+func getOrDungeons(value int, match *model.Match) int {
+	if value == 0 {
+		return len(match.Dungeons)
+	}
+	return value
+}
@@ -0,0 +1,244 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"game/model"
+	"math"
+	"strings"
+)
+
+// Metrics describes the layout of a match.
+//
+// DeadEnds is the number of dungeons with only one path. AverageDistance is
+// the average walking distance between the centers of every pair of connected
+// dungeons, following the paths.
+type Metrics struct {
+	Rooms           int
+	Paths           int
+	Diamonds        int
+	CorridorLength  int
+	DeadEnds        int
+	AverageDistance float64
+}
+
+// Report is the result of validating a match, the match is valid if there are
+// no problems.
+type Report struct {
+	Metrics  Metrics
+	Problems []string
+}
+
+func (r *Report) IsValid() bool {
+	return len(r.Problems) == 0
+}
+
+// Err returns an error describing the problems, or nil if the match is valid.
+func (r *Report) Err() error {
+	if r.IsValid() {
+		return nil
+	}
+	return errors.New(strings.Join(r.Problems, "; "))
+}
+
+func (r *Report) addProblem(format string, a ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, a...))
+}
+
+// Validate checks that every dungeon is connected, no path or dungeon overlaps
+// a dungeon it's not meant to, and every diamond can be reached by a runner
+// spawned at the first dungeon. It also measures the match layout.
+func Validate(match *model.Match) *Report {
+	report := &Report{}
+	graph := newMatchGraph(match)
+
+	report.Metrics = graph.measure()
+	report.Metrics.Diamonds = len(match.Diamonds)
+
+	checkOverlaps(match, graph, report)
+	checkConnectivity(graph, report)
+	checkDiamonds(match, report)
+	return report
+}
+
+func checkOverlaps(match *model.Match, graph *matchGraph, report *Report) {
+	for i, d1 := range match.Dungeons {
+		for j := i + 1; j < len(match.Dungeons); j++ {
+			d2 := match.Dungeons[j]
+			rect := d2.Rect()
+
+			if d1.Intersects(&rect) {
+				report.addProblem("dungeons %d and %d overlap", i, j)
+			}
+		}
+	}
+	for i, path := range match.Paths {
+		ends := graph.ends[i]
+
+		if ends[0] == -1 || ends[1] == -1 {
+			report.addProblem("path %d doesn't connect two dungeons", i)
+		}
+		for j, dungeon := range match.Dungeons {
+			if j != ends[0] && j != ends[1] && dungeon.IntersectsPath(path) {
+				report.addProblem("path %d crosses dungeon %d", i, j)
+			}
+		}
+	}
+}
+
+func checkConnectivity(graph *matchGraph, report *Report) {
+	if len(graph.adjacency) == 0 {
+		report.addProblem("there are no dungeons")
+		return
+	}
+	distances := graph.distancesFrom(0)
+	unreachable := 0
+
+	for _, distance := range distances {
+		if distance == math.MaxInt32 {
+			unreachable++
+		}
+	}
+	if unreachable > 0 {
+		report.addProblem("%d dungeons are disconnected", unreachable)
+	}
+}
+
+func checkDiamonds(match *model.Match, report *Report) {
+	if len(match.Dungeons) == 0 {
+		return
+	}
+	start := match.Dungeons[0].Center()
+
+	for i, diamond := range match.Diamonds {
+		rect := diamond.Rect()
+		route, ok := match.Nav().Route(start, rect.Center())
+
+		// The runner must pick the diamond up where the route ends
+		if !ok || !collidesAt(diamond, route[len(route)-1]) {
+			report.addProblem("diamond %d is unreachable", i)
+		}
+	}
+}
+
+// collidesAt tells whether a runner centered at the given point picks the
+// diamond up.
+func collidesAt(diamond *model.Diamond, center model.Point) bool {
+	runner := model.NewRunner()
+	runner.SetPosition(center.X()-runner.Rect.Width()/2, center.Y()-runner.Rect.Height()/2)
+	return runner.CheckDiamondCollision(diamond)
+}
+
+// matchGraph is the graph of dungeons connected by paths.
+type matchGraph struct {
+	match     *model.Match
+	adjacency [][]matchGraphEdge
+	ends      [][2]int
+}
+
+type matchGraphEdge struct {
+	to     int
+	length int
+}
+
+func (g *matchGraph) measure() Metrics {
+	metrics := Metrics{
+		Rooms: len(g.match.Dungeons),
+		Paths: len(g.match.Paths),
+	}
+	pairs := 0
+	total := 0
+
+	for _, path := range g.match.Paths {
+		metrics.CorridorLength += path.Length()
+	}
+	for i, edges := range g.adjacency {
+		if len(edges) == 1 {
+			metrics.DeadEnds++
+		}
+		for j, distance := range g.distancesFrom(i) {
+			if j > i && distance != math.MaxInt32 {
+				pairs++
+				total += distance
+			}
+		}
+	}
+	if pairs > 0 {
+		metrics.AverageDistance = float64(total) / float64(pairs)
+	}
+	return metrics
+}
+
+// dungeonAt returns the index of the dungeon containing the point, or -1 if
+// it's not within any dungeon.
+func (g *matchGraph) dungeonAt(point model.Point) int {
+	for i, dungeon := range g.match.Dungeons {
+		if dungeon.Contains(&point) {
+			return i
+		}
+	}
+	return -1
+}
+
+// distancesFrom returns the walking distance from the given dungeon to every
+// other one, or math.MaxInt32 for the ones that can't be reached.
+func (g *matchGraph) distancesFrom(source int) []int {
+	n := len(g.adjacency)
+	distances := make([]int, n)
+	done := make([]bool, n)
+
+	for i := range distances {
+		distances[i] = math.MaxInt32
+	}
+	distances[source] = 0
+
+	// Dijkstra's algorithm, dense graphs of a few hundred dungeons at most
+	for {
+		next := -1
+
+		for i := range distances {
+			if !done[i] && distances[i] != math.MaxInt32 && (next == -1 || distances[i] < distances[next]) {
+				next = i
+			}
+		}
+		if next == -1 {
+			return distances
+		}
+		done[next] = true
+
+		for _, edge := range g.adjacency[next] {
+			if d := distances[next] + edge.length; d < distances[edge.to] {
+				distances[edge.to] = d
+			}
+		}
+	}
+}
+
+func newMatchGraph(match *model.Match) *matchGraph {
+	graph := &matchGraph{
+		match:     match,
+		adjacency: make([][]matchGraphEdge, len(match.Dungeons)),
+		ends:      make([][2]int, len(match.Paths)),
+	}
+
+	for i, path := range match.Paths {
+		points := path.Points()
+		a := graph.dungeonAt(points[0])
+		b := graph.dungeonAt(points[len(points)-1])
+		graph.ends[i] = [2]int{a, b}
+
+		if a == -1 || b == -1 || a == b {
+			continue
+		}
+		length := model.Distance(match.Dungeons[a].Center(), points[0]) +
+			path.Length() +
+			model.Distance(points[len(points)-1], match.Dungeons[b].Center())
+		graph.adjacency[a] = append(graph.adjacency[a], matchGraphEdge{b, length})
+		graph.adjacency[b] = append(graph.adjacency[b], matchGraphEdge{a, length})
+	}
+	return graph
+}
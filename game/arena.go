@@ -5,6 +5,7 @@
 package main
 
 import (
+	"game/client"
 	"game/model"
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -12,6 +13,7 @@ import (
 type Arena struct {
 	player            *model.Player
 	remotePlayers     []*model.Player
+	keys              []keyBinding
 	onCharacterMotion OnCharacterMotion
 }
 
@@ -28,11 +30,11 @@ func (a *Arena) Update(update SetCurrentDungeonAndPaths) {
 	a.updateRemotePlayers(update)
 }
 
-func (a *Arena) Draw(screen *ebiten.Image) {
-	a.player.Draw(screen)
+func (a *Arena) Draw(screen *ebiten.Image, view *model.View) {
+	a.player.Draw(screen, view)
 
 	for _, player := range a.remotePlayers {
-		player.Draw(screen)
+		player.Draw(screen, view)
 	}
 }
 
@@ -61,18 +63,9 @@ func (a *Arena) updatePlayerInputs() {
 		}
 	}
 
-	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
-		if ebiten.IsKeyPressed(k) {
-			switch k {
-			case ebiten.KeyUp, ebiten.KeyW:
-				pushInput(model.MoveDirTop)
-			case ebiten.KeyDown, ebiten.KeyS:
-				pushInput(model.MoveDirBottom)
-			case ebiten.KeyLeft, ebiten.KeyA:
-				pushInput(model.MoveDirLeft)
-			case ebiten.KeyRight, ebiten.KeyD:
-				pushInput(model.MoveDirRight)
-			}
+	for _, binding := range a.keys {
+		if ebiten.IsKeyPressed(binding.key) {
+			pushInput(binding.direction)
 		}
 	}
 }
@@ -89,13 +82,18 @@ func (a *Arena) updateRemotePlayers(update SetCurrentDungeonAndPaths) {
 func (a *Arena) checkDiamondCollision(diamond *model.Diamond) bool {
 	collides := a.player.GetCharacter().CheckDiamondCollision(diamond)
 
+	// Predicted until the server sends the score
 	if collides {
-		a.player.SetScore(a.player.GetScore() + 30)
+		a.player.SetScore(a.player.GetScore() + diamond.Value().Points)
 		return true
 	}
 	return false
 }
 
+func (a *Arena) checkPickupCollision(pickup *model.Pickup) bool {
+	return a.player.GetCharacter().CheckPickupCollision(pickup)
+}
+
 func (a *Arena) SetRemotePlayerPosition(id int, point *model.Point) {
 	for _, player := range a.remotePlayers {
 		if player.Id == id {
@@ -105,12 +103,66 @@ func (a *Arena) SetRemotePlayerPosition(id int, point *model.Point) {
 	}
 }
 
-func (a *Arena) PushRemotePlayer(id int, name string, score int) {
-	player := buildPlayer(id, name)
-	player.SetScore(score)
+// StunPlayer stuns the player with the given id, local or remote, for the
+// given number of frames.
+func (a *Arena) StunPlayer(id int, frames int) {
+	if runner := a.getCharacter(id); runner != nil {
+		runner.Stun(frames)
+	}
+}
+
+// ApplyEffect gives the player with the given id the effect of the given kind
+// of pickup for the given number of frames.
+func (a *Arena) ApplyEffect(id int, kind model.PickupKind, frames int) {
+	if runner := a.getCharacter(id); runner != nil {
+		runner.ApplyEffect(kind, frames)
+	}
+}
+
+func (a *Arena) RemoveEffect(id int, kind model.PickupKind) {
+	if runner := a.getCharacter(id); runner != nil {
+		runner.RemoveEffect(kind)
+	}
+}
+
+// getCharacter returns the runner of the player with the given id, local or
+// remote, or nil if there's no such a player.
+func (a *Arena) getCharacter(id int) *model.Runner {
+	if a.player.Id == id {
+		return a.player.GetCharacter()
+	}
+	for _, player := range a.remotePlayers {
+		if player.Id == id {
+			return player.GetCharacter()
+		}
+	}
+	return nil
+}
+
+func (a *Arena) PushRemotePlayer(join *client.PlayerJoin) {
+	player := buildPlayer(join.Id, join.Name)
+	player.SetScore(join.Score)
+	player.SetBot(join.Bot)
+	player.SetReady(join.Ready)
 	a.remotePlayers = append(a.remotePlayers, player)
 }
 
+// SetRemotePlayerReady marks the player with the given id as playing the
+// match.
+func (a *Arena) SetRemotePlayerReady(id int) {
+	for _, player := range a.remotePlayers {
+		if player.Id == id {
+			player.SetReady(true)
+			break
+		}
+	}
+}
+
+// Players returns the local player followed by the remote ones.
+func (a *Arena) Players() []*model.Player {
+	return append([]*model.Player{a.player}, a.remotePlayers...)
+}
+
 func (a *Arena) RemoveRemotePlayer(lid int) {
 	index := -1
 
@@ -128,18 +180,24 @@ func (a *Arena) RemoveRemotePlayer(lid int) {
 	}
 }
 
-func (a *Arena) SetRemotePlayerScore(id int) {
+// ClearRemotePlayers removes every other player, as when the connection to
+// the server drops.
+func (a *Arena) ClearRemotePlayers() {
+	a.remotePlayers = []*model.Player{}
+}
+
+func (a *Arena) SetRemotePlayerScore(id int, score int) {
 	for _, player := range a.remotePlayers {
 		if player.Id == id {
-			player.SetScore(player.GetScore() + 30)
+			player.SetScore(score)
 			break
 		}
 	}
 }
 
-func NewArena(playerName string) Arena {
+func NewArena(playerName string, keys []keyBinding) Arena {
 	player := model.NewPlayer(playerName)
-	return Arena{player: &player, remotePlayers: []*model.Player{}}
+	return Arena{player: &player, remotePlayers: []*model.Player{}, keys: keys}
 }
 
 type OnCharacterMotion func(int)
@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package main
+
+import (
+	"game/client"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// serverInfo is what the browser knows about a server, its status or why it
+// couldn't get it.
+type serverInfo struct {
+	status  *client.ServerStatus
+	err     error
+	loading bool
+}
+
+// browserScene lists the servers of the config with their players, to join
+// one of them or add new ones.
+type browserScene struct {
+	game     *Game
+	selected int
+	adding   string
+	mu       sync.Mutex
+	infos    map[string]serverInfo
+}
+
+func (s *browserScene) Update() error {
+	servers := s.game.config.Servers
+	s.selected = moveSelection(s.selected, len(servers)+1)
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		s.game.setScene(newMenuScene(s.game))
+		return nil
+	}
+
+	// The last row adds a server
+	if s.selected == len(servers) {
+		s.adding = editText(s.adding, -1)
+
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && s.adding != "" && !containsServer(servers, s.adding) {
+			value := s.game.config
+			value.Servers = append(append([]string(nil), servers...), s.adding)
+			s.game.setConfig(value)
+			s.refresh(s.adding)
+			s.adding = ""
+		}
+		return nil
+	}
+	addr := servers[s.selected]
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		s.game.play(addr)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDelete):
+		value := s.game.config
+		value.Servers = append(append([]string(nil), servers[:s.selected]...), servers[s.selected+1:]...)
+		s.game.setConfig(value)
+	case inpututil.IsKeyJustPressed(ebiten.KeyR):
+		for _, server := range servers {
+			s.refresh(server)
+		}
+	}
+	return nil
+}
+
+func (s *browserScene) Draw(screen *ebiten.Image) {
+	drawTitle(screen, "Servers")
+	servers := s.game.config.Servers
+	rows := make([]string, 0, len(servers)+1)
+
+	s.mu.Lock()
+	for _, server := range servers {
+		rows = append(rows, server+"   "+describeServer(s.infos[server]))
+	}
+	s.mu.Unlock()
+
+	adding := "Add: " + s.adding
+
+	if s.selected == len(servers) {
+		adding += "_"
+	}
+	rows = append(rows, adding)
+	drawList(screen, rows, s.selected, 240)
+	drawHint(screen, "Enter to join or add, Delete to remove, R to refresh, Esc to go back")
+}
+
+// refresh fetches the status of the given server in the background.
+func (s *browserScene) refresh(server string) {
+	s.mu.Lock()
+	s.infos[server] = serverInfo{loading: true}
+	s.mu.Unlock()
+
+	u := s.game.config.ServerURL(server)
+
+	go func() {
+		status, err := client.FetchStatus(u)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.infos[server] = serverInfo{status: status, err: err}
+	}()
+}
+
+func describeServer(info serverInfo) string {
+	switch {
+	case info.loading:
+		return "..."
+	case info.err != nil:
+		return "offline"
+	case info.status == nil:
+		return ""
+	}
+	seconds := int(info.status.RemainingTime / time.Second)
+	return strconv.Itoa(info.status.Players) + " players, " +
+		strconv.Itoa(info.status.Bots) + " bots, " +
+		strconv.Itoa(seconds) + "s left"
+}
+
+func containsServer(servers []string, server string) bool {
+	for _, s := range servers {
+		if s == server {
+			return true
+		}
+	}
+	return false
+}
+
+func newBrowserScene(game *Game) *browserScene {
+	scene := &browserScene{
+		game:  game,
+		infos: map[string]serverInfo{},
+	}
+
+	for _, server := range game.config.Servers {
+		scene.refresh(server)
+	}
+	return scene
+}
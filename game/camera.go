@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package main
+
+import (
+	"game/model"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"math"
+)
+
+const (
+	minZoom  = 0.5
+	maxZoom  = 2.0
+	zoomStep = 1.25
+)
+
+// camera follows the runner of the player over worlds larger than the
+// screen. The world is drawn to a canvas the size of the view, which is
+// scaled to the screen by the zoom.
+type camera struct {
+	cx     float64
+	cy     float64
+	zoom   float64
+	canvas *ebiten.Image
+}
+
+// Update zooms with the keys or the wheel and centers the view on the given
+// rect, without showing past the edges of the world unless it's smaller than
+// the view.
+func (c *camera) Update(target model.Rect, world model.Rect) {
+	_, wheel := ebiten.Wheel()
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyKPAdd) || wheel > 0:
+		c.zoom = math.Min(c.zoom*zoomStep, maxZoom)
+	case inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyKPSubtract) || wheel < 0:
+		c.zoom = math.Max(c.zoom/zoomStep, minZoom)
+	case inpututil.IsKeyJustPressed(ebiten.Key0):
+		c.zoom = 1
+	}
+	width, height := c.size()
+	c.cx = follow(float64(target.Cx()), width, world.Left(), world.Right())
+	c.cy = follow(float64(target.Cy()), height, world.Top(), world.Bottom())
+}
+
+// View returns the part of the world the camera shows.
+func (c *camera) View() model.View {
+	width, height := c.size()
+	left := int(math.Round(c.cx - width/2))
+	top := int(math.Round(c.cy - height/2))
+	return model.NewView(left, top, int(math.Ceil(width)), int(math.Ceil(height)))
+}
+
+// Canvas returns the cleared image to draw the view of the world to.
+func (c *camera) Canvas() *ebiten.Image {
+	width, height := c.size()
+	w := int(math.Ceil(width))
+	h := int(math.Ceil(height))
+
+	if c.canvas == nil || c.canvas.Bounds().Dx() != w || c.canvas.Bounds().Dy() != h {
+		if c.canvas != nil {
+			c.canvas.Dispose()
+		}
+		c.canvas = ebiten.NewImage(w, h)
+	}
+	c.canvas.Clear()
+	return c.canvas
+}
+
+// Draw draws the canvas to the screen at the zoom of the camera.
+func (c *camera) Draw(screen *ebiten.Image) {
+	op := &ebiten.DrawImageOptions{}
+
+	op.GeoM.Scale(c.zoom, c.zoom)
+	screen.DrawImage(c.canvas, op)
+}
+
+// size returns the width and height of the world the screen fits.
+func (c *camera) size() (float64, float64) {
+	return screenWidth / c.zoom, screenHeight / c.zoom
+}
+
+func newCamera() camera {
+	return camera{zoom: 1}
+}
+
+// follow returns the center of a view of the given length on an axis, at the
+// given target unless that shows past the start or end of the world.
+func follow(target float64, length float64, start int, end int) float64 {
+	if length >= float64(end-start) {
+		return float64(start+end) / 2
+	}
+	return math.Max(float64(start)+length/2, math.Min(target, float64(end)-length/2))
+}
+
+// worldRect returns the bounds of the world of the given match, from the
+// origin to the farthest dungeon.
+func worldRect(match *model.Match) model.Rect {
+	width := 1
+	height := 1
+
+	for _, dungeon := range match.Dungeons {
+		rect := dungeon.Rect()
+		width = max(width, rect.Right())
+		height = max(height, rect.Bottom())
+	}
+	return model.NewRect(0, 0, width, height)
+}
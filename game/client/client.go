@@ -5,21 +5,23 @@
 package client
 
 import (
-	"bufio"
 	"encoding/json"
-	"flag"
+	"errors"
+	"fmt"
+	"game/ai"
 	"game/model"
 	"log"
+	"net/http"
 	"net/url"
-	"os"
-	"os/signal"
 	"strconv"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
-var addr = flag.String("addr", "localhost:8080", "http service address")
+// ErrDisconnected is returned when the connection drops after the server
+// accepted the player.
+var ErrDisconnected = errors.New("disconnected from the server")
 
 type ResponseData struct {
 	Type int
@@ -30,28 +32,138 @@ type JoinAccepted struct {
 	Id int
 }
 
+// PlayerJoin announces a player, Bot tells that it's played by the server.
+// Ready tells that it left the lobby and is playing the match, until then
+// it's announced by a PlayerReady message with its id.
 type PlayerJoin struct {
 	Id        int
 	Name      string
 	PointJSON model.PointJSON
 	Score     int
+	Bot       bool
+	Ready     bool
 }
 
+// MatchEnd announces the results of the match that just ended, ranked from
+// the winner. The next match starts after the Intermission.
+type MatchEnd struct {
+	Results      []*PlayerResult
+	Intermission time.Duration
+}
+
+// PlayerResult is how a player did in a match. Players with the same score
+// share the Rank. Distance is how far its runner walked, and FirstPickup the
+// time until its first diamond, zero if it took none.
+type PlayerResult struct {
+	Id          int
+	Name        string
+	Bot         bool
+	Rank        int
+	Score       int
+	Diamonds    int
+	Distance    int
+	FirstPickup time.Duration
+}
+
+// ServerStatus is what the server browser shows about a server.
+type ServerStatus struct {
+	Players       int
+	Bots          int
+	RemainingTime time.Duration
+}
+
+// statusTimeout is how long the server browser waits for each server.
+const statusTimeout = 3 * time.Second
+
+// FetchStatus asks the server at the given websocket URL for its status.
+func FetchStatus(u url.URL) (*ServerStatus, error) {
+	scheme := "http"
+
+	if u.Scheme == "wss" {
+		scheme = "https"
+	}
+	u.Scheme = scheme
+	u.Path = "/status"
+	httpClient := &http.Client{Timeout: statusTimeout}
+	res, err := httpClient.Get(u.String())
+
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server status error: %s", res.Status)
+	}
+	status := &ServerStatus{}
+
+	if err := json.NewDecoder(res.Body).Decode(status); err != nil {
+		return nil, fmt.Errorf("server status read error: %w", err)
+	}
+	return status, nil
+}
+
+// MatchInit starts a match for the player, who spawns at the dungeon with
+// the Spawn index.
 type MatchInit struct {
 	MatchJSON     *model.MatchJSON
 	Match         *model.Match
+	Seed          int64
+	Config        ai.MatchConfig
+	Spawn         int
 	RemainingTime time.Duration
 	Players       []*PlayerJoin
 }
 
+// DiamondSpawn announces the diamonds respawned in the current match, they
+// go after the diamonds left.
+type DiamondSpawn struct {
+	DiamondsJSON []*model.DiamondJSON
+	Diamonds     []*model.Diamond
+}
+
+// PickupSpawn announces the pickups spawned in the current match, they go
+// after the pickups left.
+type PickupSpawn struct {
+	PickupsJSON []*model.PickupJSON
+	Pickups     []*model.Pickup
+}
+
+// EffectExpired announces that the effect of a pickup of the given kind is
+// over for the player with the given id.
+type EffectExpired struct {
+	Id   int
+	Kind model.PickupKind
+}
+
+// Update is the position a player sends on each frame. The server fills
+// the player score and the remaining time, as it's the one scoring diamonds.
+//
+// PickupIndex is the pickup taken like DiamondIndex, its effect is applied
+// to the player until an EffectExpired arrives.
+//
+// With collisions on, Blocked tells that the server rejected the move so the
+// position is the last one allowed, and StunnedId is the player the move
+// bumped into, who is stunned for Stun, or -1 if there's none.
 type Update struct {
 	Id int
 	//Move int // use point for now
-	PointJSON    model.PointJSON
-	DiamondIndex int
+	PointJSON     model.PointJSON
+	DiamondIndex  int
+	PickupIndex   int
+	Score         int
+	RemainingTime time.Duration
+	Blocked       bool
+	StunnedId     int
+	Stun          time.Duration
 }
 
+// Run connects to the server at the given URL and joins with the given name,
+// until quit is closed. It returns why the connection ended, wrapping
+// ErrDisconnected if the player was playing.
 func Run(
+	quit chan struct{},
+	u url.URL,
 	name string,
 	accepted chan *JoinAccepted,
 	matchCh chan *MatchInit,
@@ -59,71 +171,88 @@ func Run(
 	sendUpdate chan *Update,
 	joinCh chan *PlayerJoin,
 	leaveCh chan int,
-) {
-	flag.Parse()
-	log.SetFlags(0)
-
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
-
-	u := url.URL{Scheme: "ws", Host: *addr, Path: ""}
+	readyCh chan int,
+	spawnCh chan *DiamondSpawn,
+	pickupCh chan *PickupSpawn,
+	expiredCh chan *EffectExpired,
+	matchEndCh chan *MatchEnd,
+) error {
 	log.Printf("connecting to %s", u.String())
 
 	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
 	if err != nil {
-		log.Fatal("Dial error:", err)
+		return err
 	}
 	defer conn.Close()
 
 	done := make(chan struct{})
+	errCh := make(chan error, 1)
+	closed := make(chan struct{})
+
+	defer close(closed)
+
+	// Closing the connection makes the reads fail, which ends the loops
+	go func() {
+		select {
+		case <-quit:
+			conn.Close()
+		case <-closed:
+		}
+	}()
 
-	waitAccepted(name, accepted, conn)
-	readMessages(done, conn, matchCh, ch, joinCh, leaveCh)
+	if err := waitAccepted(name, accepted, conn); err != nil {
+		return err
+	}
+	readMessages(done, errCh, conn, matchCh, ch, joinCh, leaveCh, readyCh, spawnCh, pickupCh, expiredCh, matchEndCh)
 	writeMessages(done, conn, sendUpdate)
 
-	reader := bufio.NewReader(os.Stdin)
-	reader.ReadString('\n')
+	<-done
+	return fmt.Errorf("%w: %v", ErrDisconnected, <-errCh)
 }
 
-func waitAccepted(name string, acceptedCh chan *JoinAccepted, conn *websocket.Conn) {
+func waitAccepted(name string, acceptedCh chan *JoinAccepted, conn *websocket.Conn) error {
 	if err := conn.WriteMessage(websocket.TextMessage, []byte(name)); err != nil {
-		log.Println("Name write error:", err)
-		return
+		return fmt.Errorf("name write error: %w", err)
 	}
 
 	_, p, err := conn.ReadMessage()
 
 	if err != nil {
-		log.Println("Read error:", err)
-		return
+		return fmt.Errorf("read error: %w", err)
 	}
 	data := &ResponseData{}
 
 	if err := json.Unmarshal(p, data); err != nil {
-		log.Println("Read ResponseData error:", err)
-		return
+		return fmt.Errorf("read ResponseData error: %w", err)
 	}
 
 	if data.Type != 3 {
-		log.Println("Failed to connect, invalid server accepted response")
-		return
+		return errors.New("invalid server accepted response")
 	}
 	accepted := &JoinAccepted{}
 
 	if err := json.Unmarshal([]byte(data.Body), accepted); err != nil {
-		log.Println("JoinAccepted read error:", err)
-		return
+		return fmt.Errorf("JoinAccepted read error: %w", err)
 	}
 	acceptedCh <- accepted
+	return nil
 }
 
+// readMessages reads the messages of the server until the connection fails,
+// then it sends why to errCh and closes done.
 func readMessages(
 	done chan struct{},
+	errCh chan error,
 	conn *websocket.Conn,
 	h chan *MatchInit,
 	ch chan *Update,
 	joinCh chan *PlayerJoin,
 	leaveCh chan int,
+	readyCh chan int,
+	spawnCh chan *DiamondSpawn,
+	pickupCh chan *PickupSpawn,
+	expiredCh chan *EffectExpired,
+	matchEndCh chan *MatchEnd,
 ) {
 	init := func(body string) {
 		matchInit := &MatchInit{}
@@ -167,6 +296,62 @@ func readMessages(
 		leaveCh <- id
 	}
 
+	ready := func(body string) {
+		id, err := strconv.Atoi(body)
+
+		if err != nil {
+			log.Println("Player ready error:", err)
+			return
+		}
+		readyCh <- id
+	}
+
+	spawn := func(body string) {
+		spawn := &DiamondSpawn{}
+
+		if err := json.Unmarshal([]byte(body), spawn); err != nil {
+			log.Println("Diamond spawn read error:", err)
+			return
+		}
+		for _, diamondJSON := range spawn.DiamondsJSON {
+			spawn.Diamonds = append(spawn.Diamonds, diamondJSON.ToDiamond())
+		}
+		spawnCh <- spawn
+	}
+
+	pickup := func(body string) {
+		spawn := &PickupSpawn{}
+
+		if err := json.Unmarshal([]byte(body), spawn); err != nil {
+			log.Println("Pickup spawn read error:", err)
+			return
+		}
+		for _, pickupJSON := range spawn.PickupsJSON {
+			spawn.Pickups = append(spawn.Pickups, pickupJSON.ToPickup())
+		}
+		pickupCh <- spawn
+	}
+
+	expired := func(body string) {
+		expired := &EffectExpired{}
+
+		if err := json.Unmarshal([]byte(body), expired); err != nil {
+			log.Println("Effect expired read error:", err)
+			return
+		}
+		expiredCh <- expired
+	}
+
+	end := func(body string) {
+		end := &MatchEnd{}
+
+		if err := json.Unmarshal([]byte(body), end); err != nil {
+			log.Println("Match end read error:", err)
+			return
+		}
+		matchEndCh <- end
+	}
+
 	readResponse := func(data *ResponseData) {
 		switch data.Type {
 		case 0:
@@ -177,6 +362,16 @@ func readMessages(
 			join(data.Body)
 		case 5:
 			leave(data.Body)
+		case 6:
+			spawn(data.Body)
+		case 7:
+			pickup(data.Body)
+		case 8:
+			expired(data.Body)
+		case 9:
+			ready(data.Body)
+		case 10:
+			end(data.Body)
 		}
 	}
 
@@ -186,14 +381,14 @@ func readMessages(
 			_, p, err := conn.ReadMessage()
 
 			if err != nil {
-				log.Println("Read error:", err)
+				errCh <- err
 				return
 			}
 			//log.Printf("recv: %s", p)
 			data := &ResponseData{}
 
 			if err := json.Unmarshal(p, data); err != nil {
-				log.Println("Read ResponseData error:", err)
+				errCh <- fmt.Errorf("read ResponseData error: %w", err)
 				return
 			}
 			//log.Printf("Response: %+v\n", data)
@@ -0,0 +1,179 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+// Package config loads the settings of the game client, from a file in the
+// user config dir overridden by the environment and the command line.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EnvPath is the environment variable with the path of the config file.
+	EnvPath = "DUNGEON_MST_CONFIG"
+	EnvAddr = "DUNGEON_MST_ADDR"
+	EnvTLS  = "DUNGEON_MST_TLS"
+	EnvName = "DUNGEON_MST_NAME"
+)
+
+// MaxNameLength is the most characters a player name can have.
+const MaxNameLength = 16
+
+var ErrInvalid = errors.New("invalid config")
+
+// Default returns the config of a player who hasn't set up the game yet, it
+// plays on a local server with the arrows or WASD.
+func Default() Config {
+	return Config{
+		Addr:    "localhost:8080",
+		Servers: []string{"localhost:8080"},
+		Keys: KeyBindings{
+			Up:    []string{"Up", "W"},
+			Down:  []string{"Down", "S"},
+			Left:  []string{"Left", "A"},
+			Right: []string{"Right", "D"},
+		},
+	}
+}
+
+// Config is what the game needs to connect to a server and play.
+//
+// Addr is the host and port of the server, TLS connects to it over a secure
+// websocket. Servers are the addresses the server browser lists. Name is how
+// other players see this one.
+type Config struct {
+	Addr    string
+	TLS     bool
+	Servers []string
+	Name    string
+	Keys    KeyBindings
+}
+
+// KeyBindings are the names of the keys that move the runner towards each
+// direction, as ebiten names them.
+type KeyBindings struct {
+	Up    []string
+	Down  []string
+	Left  []string
+	Right []string
+}
+
+// URL returns the websocket URL of the server.
+func (c *Config) URL() url.URL {
+	return c.ServerURL(c.Addr)
+}
+
+// ServerURL returns the websocket URL of the server at the given address.
+func (c *Config) ServerURL(addr string) url.URL {
+	scheme := "ws"
+
+	if c.TLS {
+		scheme = "wss"
+	}
+	return url.URL{Scheme: scheme, Host: addr, Path: ""}
+}
+
+// Validate returns an error if the game can't connect with this config.
+func (c *Config) Validate() error {
+	if strings.TrimSpace(c.Addr) == "" {
+		return fmt.Errorf("%w: the server address is empty", ErrInvalid)
+	}
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("%w: the player name is empty", ErrInvalid)
+	}
+	if len([]rune(c.Name)) > MaxNameLength {
+		return fmt.Errorf("%w: the player name is longer than %d characters", ErrInvalid, MaxNameLength)
+	}
+	return nil
+}
+
+// Path returns where the config file is, the one set in the environment or
+// the one in the user config dir.
+func Path() (string, error) {
+	if path, ok := os.LookupEnv(EnvPath); ok {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "dungeon-mst", "config.json"), nil
+}
+
+// Load reads the config file at the given path on top of the default
+// config. It returns false if there's no file yet.
+func Load(path string) (Config, bool, error) {
+	config := Default()
+	content, err := ioutil.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return config, false, nil
+	}
+	if err != nil {
+		return config, false, err
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return config, false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return config, true, nil
+}
+
+// Save writes the config file at the given path, creating its dir if needed.
+func Save(path string, config Config) error {
+	enc, err := json.MarshalIndent(config, "", "  ")
+
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, enc, 0644)
+}
+
+// ApplyEnv overrides the config with the environment variables set.
+func ApplyEnv(config *Config, lookup func(string) (string, bool)) error {
+	if addr, ok := lookup(EnvAddr); ok {
+		config.Addr = addr
+	}
+	if name, ok := lookup(EnvName); ok {
+		config.Name = name
+	}
+	if value, ok := lookup(EnvTLS); ok {
+		tls, err := strconv.ParseBool(value)
+
+		if err != nil {
+			return fmt.Errorf("%w: %s must be a boolean", ErrInvalid, EnvTLS)
+		}
+		config.TLS = tls
+	}
+	return nil
+}
+
+// ApplyFlags overrides the config with the flags set in the command line.
+func ApplyFlags(config *Config, args []string) error {
+	flags := flag.NewFlagSet("game", flag.ContinueOnError)
+	addr := flags.String("addr", config.Addr, "server address")
+	tls := flags.Bool("tls", config.TLS, "connect over a secure websocket")
+	name := flags.String("name", config.Name, "player name")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	config.Addr = *addr
+	config.TLS = *tls
+	config.Name = *name
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "dungeon-mst", "config.json")
+
+	if _, found, err := Load(path); found || err != nil {
+		t.Fatal("FAILED a missing file must load the default config:", err)
+	}
+	saved := Default()
+	saved.Name = "Tobias"
+	saved.Addr = "example.com:8080"
+
+	if err := Save(path, saved); err != nil {
+		t.Fatal("FAILED to save config:", err)
+	}
+	config, found, err := Load(path)
+
+	if !found || err != nil || config.Name != "Tobias" || len(config.Keys.Up) != 2 {
+		t.Fatal("FAILED to load the saved config:", err)
+	}
+	env := map[string]string{EnvAddr: "env.com:443", EnvTLS: "true"}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	if err := ApplyEnv(&config, lookup); err != nil {
+		t.Fatal("FAILED to apply env:", err)
+	}
+	if err := ApplyFlags(&config, []string{"-name", "Flag"}); err != nil {
+		t.Fatal("FAILED to apply flags:", err)
+	}
+	if config.Name != "Flag" || config.Addr != "env.com:443" || !config.TLS {
+		t.Fatalf("FAILED to override config: %+v", config)
+	}
+	if u := config.URL(); u.String() != "wss://env.com:443" {
+		t.Fatal("FAILED to build the server URL:", u.String())
+	}
+	env[EnvTLS] = "maybe"
+
+	if err := ApplyEnv(&config, lookup); err == nil {
+		t.Fatal("FAILED invalid TLS env must be an error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	config := Default()
+
+	if err := config.Validate(); err == nil {
+		t.Fatal("FAILED the player name is required")
+	}
+	config.Name = "Tobias"
+
+	if err := config.Validate(); err != nil {
+		t.Fatal("FAILED to validate config:", err)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package main
+
+import (
+	"errors"
+	"game/client"
+	"game/config"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// minRetryDelay is how long the game waits to connect again after the
+	// first failure, it doubles on each failure up to maxRetryDelay.
+	minRetryDelay = time.Second
+	maxRetryDelay = 30 * time.Second
+)
+
+// connectionStatus is what the connecting screen tells the player about the
+// connection to the server.
+type connectionStatus struct {
+	mu      sync.Mutex
+	message string
+	err     error
+	retryAt time.Time
+	attempt int
+}
+
+// Get returns the status message, when the next retry is, and the last
+// connection error if any.
+func (s *connectionStatus) Get() (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		return s.message, "", nil
+	}
+	seconds := int(time.Until(s.retryAt).Seconds()) + 1
+	retry := "Retrying in " + strconv.Itoa(seconds) + "s (attempt " + strconv.Itoa(s.attempt) + ")"
+	return s.message, retry, s.err
+}
+
+func (s *connectionStatus) set(message string, err error, retryAt time.Time, attempt int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.message = message
+	s.err = err
+	s.retryAt = retryAt
+	s.attempt = attempt
+}
+
+// keepConnected connects to the server of the given config, and again
+// whenever the connection fails or drops, waiting longer after each failure
+// in a row. It returns once stop is closed.
+func (g *Game) keepConnected(value config.Config, stop chan struct{}) {
+	u := value.URL()
+	delay := minRetryDelay
+	attempt := 1
+
+	for {
+		g.status.set("Connecting to "+u.String(), nil, time.Time{}, attempt)
+
+		err := client.Run(
+			stop,
+			u,
+			value.Name,
+			g.acceptedCh,
+			g.matchCh,
+			g.updateCh,
+			g.sendUpdateCh,
+			g.joinCh,
+			g.leaveCh,
+			g.readyCh,
+			g.spawnCh,
+			g.pickupCh,
+			g.expiredCh,
+			g.matchEndCh,
+		)
+		log.Println("Connection error:", err)
+
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		message := "Can't connect to " + u.String()
+
+		if errors.Is(err, client.ErrDisconnected) {
+			// It was playing, so the server may just be restarting
+			g.disconnectCh <- struct{}{}
+			message = "Disconnected from " + u.String()
+			delay = minRetryDelay
+			attempt = 0
+		}
+		attempt++
+		g.status.set(message, err, time.Now().Add(delay), attempt)
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
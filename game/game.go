@@ -5,19 +5,22 @@
 package main
 
 import (
-	"encoding/json"
+	"game/ai"
 	"game/client"
+	"game/config"
 	"game/model"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
 	"image/color"
-	"io/ioutil"
 	"log"
 	"math/rand"
+	"os"
+	"sort"
 	"strconv"
 	"time"
 )
@@ -27,6 +30,10 @@ const (
 	screenHeight = 720
 )
 
+// takeTimeout is how long the player waits for the server to confirm taking
+// a diamond before trying again.
+const takeTimeout = time.Second
+
 var (
 	bgImage *ebiten.Image
 	user    User
@@ -39,24 +46,67 @@ type User struct {
 
 type Game struct {
 	match         *model.Match
+	spawn         int
 	arena         *Arena
 	count         int
 	legendImage   *ebiten.Image
+	hud           hud
+	respawns      bool
+	taking        *model.Diamond
+	takingFrames  int
+	camera        camera
+	keys          []keyBinding
+	config        config.Config
+	configPath    string
+	scene         Scene
+	status        *connectionStatus
+	stop          chan struct{}
+	disconnectCh  chan struct{}
+	acceptedCh    chan *client.JoinAccepted
 	matchCh       chan *client.MatchInit
 	updateCh      chan *client.Update
 	sendUpdateCh  chan *client.Update
 	joinCh        chan *client.PlayerJoin
 	leaveCh       chan int
-	quit          chan bool
+	readyCh       chan int
+	spawnCh       chan *client.DiamondSpawn
+	pickupCh      chan *client.PickupSpawn
+	expiredCh     chan *client.EffectExpired
+	matchEndCh    chan *client.MatchEnd
 	remainingTime time.Duration
 }
 
+// removeDiamond removes the diamond the server says was taken, if any.
+func (g *Game) removeDiamond(index int) {
+	if g.match == nil || index < 0 || index >= len(g.match.Diamonds) {
+		return
+	}
+	if g.match.Diamonds[index] == g.taking {
+		g.taking = nil
+	}
+	g.match.Diamonds = remove(g.match.Diamonds, index)
+}
+
+// IsPaused tells whether the match is on hold, once its time is up or when
+// there are no diamonds left and none respawn.
 func (g *Game) IsPaused() bool {
-	return len(g.match.Diamonds) == 0
+	if g.remainingTime <= 0 {
+		return true
+	}
+	return len(g.match.Diamonds) == 0 && !g.respawns
 }
 
-func (g *Game) SetMatch(value *model.Match) {
+// SetMatch starts the given match with the player at the spawn dungeon the
+// server assigned.
+func (g *Game) SetMatch(value *model.Match, spawn int) {
+	if spawn < 0 || spawn >= len(value.Dungeons) {
+		spawn = 0
+	}
 	g.match = value
+	g.spawn = spawn
+	g.taking = nil
+
+	g.arena.player.GetCharacter().SetDungeon(value.Dungeons[spawn])
 
 	g.arena.player.SetScore(0)
 
@@ -66,6 +116,108 @@ func (g *Game) SetMatch(value *model.Match) {
 }
 
 func (g *Game) Update() error {
+	g.receive()
+
+	// The server sends the time left with every update, this keeps it going
+	// in between
+	if g.match != nil {
+		g.remainingTime -= time.Second / time.Duration(ebiten.MaxTPS())
+	}
+	select {
+	case <-g.disconnectCh:
+		g.disconnect()
+	case end := <-g.matchEndCh:
+		// Players still in the lobby don't have results to see
+		if _, ok := g.scene.(*matchScene); ok {
+			g.setScene(newResultsScene(g, end))
+		}
+	default:
+	}
+	return g.scene.Update()
+}
+
+// receive applies the messages the server sent since the last frame. The
+// game goroutine is the only one that changes the match and the arena.
+func (g *Game) receive() {
+	for {
+		select {
+		case accepted := <-g.acceptedCh:
+			// It comes before any other message of the connection
+			g.arena.player.Id = accepted.Id
+			user.Id = accepted.Id
+			g.status.set("Waiting for the match", nil, time.Time{}, 0)
+			log.Println("Accepted", accepted.Id)
+		case m := <-g.matchCh:
+			g.SetMatch(m.Match, m.Spawn)
+			g.remainingTime = m.RemainingTime
+			g.respawns = m.Config.Spawns.Mode == ai.WaveRespawn || m.Config.Spawns.Mode == ai.CooldownRespawn
+
+			for _, player := range m.Players {
+				g.arena.PushRemotePlayer(player)
+			}
+			go verifyMatch(m.Seed, m.Config, &model.Match{Dungeons: m.Match.Dungeons, Paths: m.Match.Paths})
+		case u := <-g.updateCh:
+			g.applyUpdate(u)
+		case j := <-g.joinCh:
+			if j.Id == user.Id {
+				continue
+			}
+			log.Println("Joining player:", j.Id)
+			g.arena.PushRemotePlayer(j)
+		case id := <-g.readyCh:
+			g.arena.SetRemotePlayerReady(id)
+		case id := <-g.leaveCh:
+			g.arena.RemoveRemotePlayer(id)
+		case spawn := <-g.spawnCh:
+			// The match the player gets next has the diamonds already
+			if g.match != nil {
+				g.match.Diamonds = append(g.match.Diamonds, spawn.Diamonds...)
+			}
+		case spawn := <-g.pickupCh:
+			// The match the player gets next has the pickups already
+			if g.match != nil {
+				g.match.Pickups = append(g.match.Pickups, spawn.Pickups...)
+			}
+		case expired := <-g.expiredCh:
+			g.arena.RemoveEffect(expired.Id, expired.Kind)
+		default:
+			return
+		}
+	}
+}
+
+// applyUpdate moves the player the update is from, and takes what it took.
+func (g *Game) applyUpdate(u *client.Update) {
+	g.remainingTime = u.RemainingTime
+
+	if u.StunnedId != -1 {
+		g.arena.StunPlayer(u.StunnedId, toFrames(u.Stun))
+	}
+	g.removeDiamond(u.DiamondIndex)
+
+	if u.Id == user.Id {
+		g.arena.player.SetScore(u.Score)
+
+		// The server rejected the move
+		if u.Blocked {
+			point := u.PointJSON.ToPoint()
+			g.arena.player.SetPosition(point.X(), point.Y())
+		}
+		return
+	}
+	g.arena.SetRemotePlayerPosition(u.Id, u.PointJSON.ToPoint())
+
+	if g.match != nil && u.PickupIndex != -1 && u.PickupIndex < len(g.match.Pickups) {
+		pickup := g.match.Pickups[u.PickupIndex]
+		g.match.Pickups = removePickup(g.match.Pickups, u.PickupIndex)
+		g.arena.ApplyEffect(u.Id, pickup.Kind(), toFrames(pickup.Duration()))
+	}
+	g.arena.SetRemotePlayerScore(u.Id, u.Score)
+}
+
+// updateMatch plays a frame of the match, sending the moves of the player to
+// the server.
+func (g *Game) updateMatch() error {
 	if g.match == nil {
 		return nil
 	}
@@ -75,15 +227,34 @@ func (g *Game) Update() error {
 	g.count++
 	diamondIndex := -1
 
+	if g.takingFrames > 0 {
+		g.takingFrames--
+	}
 	for i, diamond := range g.match.Diamonds {
+		// The diamond is removed when the server confirms the take, it's sent
+		// again if the answer doesn't come in time
+		if diamond == g.taking && g.takingFrames > 0 {
+			continue
+		}
 		if g.arena.checkDiamondCollision(diamond) {
 			diamondIndex = i
+			g.taking = diamond
+			g.takingFrames = toFrames(takeTimeout)
 			break
 		}
 	}
+	pickupIndex := -1
 
-	if diamondIndex != -1 {
-		g.match.Diamonds = remove(g.match.Diamonds, diamondIndex)
+	for i, pickup := range g.match.Pickups {
+		if g.arena.checkPickupCollision(pickup) {
+			pickupIndex = i
+			g.arena.ApplyEffect(user.Id, pickup.Kind(), toFrames(pickup.Duration()))
+			break
+		}
+	}
+
+	if pickupIndex != -1 {
+		g.match.Pickups = removePickup(g.match.Pickups, pickupIndex)
 	}
 
 	g.arena.Update(g.setCurrentDungeonAndPaths)
@@ -94,8 +265,14 @@ func (g *Game) Update() error {
 		//Move: move,
 		PointJSON:    *model.NewPointJSON(&position),
 		DiamondIndex: diamondIndex,
+		PickupIndex:  pickupIndex,
+	}
+
+	select {
+	case g.sendUpdateCh <- update:
+	case <-g.disconnectCh:
+		g.disconnect()
 	}
-	g.sendUpdateCh <- update
 
 	/*
 		// Generate random dungeons
@@ -108,35 +285,69 @@ func (g *Game) Update() error {
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
+	g.scene.Draw(screen)
+}
+
+func (g *Game) drawMatch(screen *ebiten.Image) {
 	if g.match == nil {
-		g.drawStartScreen(screen)
+		screen.Fill(sceneBackground)
 		return
 	}
 	screen.DrawImage(bgImage, nil)
 
+	// The world is drawn to the canvas of the camera, skipping what's out of
+	// its view
+	world := g.camera.Canvas()
+	view := g.camera.View()
+
 	for _, dungeon := range g.match.Dungeons {
-		dungeon.DrawBarrier(screen)
+		rect := dungeon.Rect()
+
+		if view.IsVisible(&rect) {
+			dungeon.DrawBarrier(world, &view)
+		}
 	}
 	for _, path := range g.match.Paths {
-		path.Draw(screen)
+		path.Draw(world, &view)
 	}
 	for _, dungeon := range g.match.Dungeons {
-		dungeon.Draw(screen)
-	}
+		rect := dungeon.Rect()
 
-	// Draw legend image
-	screen.DrawImage(g.legendImage, nil)
+		if view.IsVisible(&rect) {
+			dungeon.Draw(world, &view)
+		}
+	}
 
 	// Draw diamonds
 	for _, diamond := range g.match.Diamonds {
-		diamond.Draw(screen)
+		rect := diamond.Rect()
+
+		if view.IsVisible(&rect) {
+			diamond.Draw(world, &view)
+		}
+	}
+
+	// Draw pickups
+	for _, pickup := range g.match.Pickups {
+		rect := pickup.Rect()
+
+		if view.IsVisible(&rect) {
+			pickup.Draw(world, &view)
+		}
 	}
 
 	// Draw remote players
-	g.arena.Draw(screen)
+	g.arena.Draw(world, &view)
+
+	if g.arena.player.GetCharacter().HasEffect(model.PickupReveal) {
+		g.drawDiamondMarkers(world, &view)
+	}
+	g.camera.Draw(screen)
 
-	timeLeft := strconv.FormatInt(int64(g.remainingTime/time.Second), 10)
-	text.Draw(screen, timeLeft, mplusNormalFont, screenWidth-200, 96, color.White)
+	// Draw legend image
+	screen.DrawImage(g.legendImage, nil)
+
+	g.hud.Draw(screen, g)
 
 	if g.IsPaused() {
 		g.drawPauseScreen(screen)
@@ -152,168 +363,245 @@ func (g *Game) onCharacterMotion(move int) {
 }
 
 func (g *Game) setCurrentDungeonAndPaths(runner *model.Runner) {
-	var currentDungeon *model.Dungeon = nil
-	var currentPaths []*model.Path
+	g.match.SetCurrentDungeonAndPaths(runner)
 
-	for _, dungeon := range g.match.Dungeons {
-		if dungeon.InBounds(&runner.Rect) {
-			currentDungeon = dungeon
-			break
-		}
+	if runner.IsOutSide() {
+		runner.SetDungeon(g.match.Dungeons[g.spawn])
 	}
-	for _, path := range g.match.Paths {
-		if path.InBounds(&runner.Rect) {
-			currentPaths = append(currentPaths, path)
-		}
+}
+
+// disconnect drops the match and the other players until the game connects
+// again, back in the lobby if the player was playing.
+func (g *Game) disconnect() {
+	g.match = nil
+	g.arena.ClearRemotePlayers()
+	g.arena.player.SetReady(false)
+
+	if _, ok := g.scene.(*matchScene); ok {
+		g.setScene(newLobbyScene(g))
 	}
-	runner.SetCurrentDungeon(currentDungeon)
-	runner.SetCurrentPaths(currentPaths)
+}
 
-	if runner.IsOutSide() {
-		runner.SetDungeon(g.match.Dungeons[0])
+func (g *Game) setScene(scene Scene) {
+	g.scene = scene
+}
+
+// setConfig saves the given config, leaving the server if the player has to
+// join it again with the new one.
+func (g *Game) setConfig(value config.Config) {
+	if value.Addr != g.config.Addr || value.TLS != g.config.TLS || value.Name != g.config.Name {
+		g.leave()
+	}
+	g.config = value
+
+	if err := config.Save(g.configPath, value); err != nil {
+		log.Println("Failed to save config:", err)
 	}
 }
 
-func (g *Game) reset() {
-	//g.match = ai.NewRandomMatch(getSize())
+// play joins the server at the given address, if it's not there yet, and
+// waits in the lobby.
+func (g *Game) play(addr string) {
+	if addr != g.config.Addr {
+		value := g.config
+		value.Addr = addr
+		g.setConfig(value)
+	}
+	if g.stop == nil {
+		g.connectTo(g.config)
+	}
+	g.setScene(newLobbyScene(g))
 }
 
-func (g *Game) drawStartScreen(screen *ebiten.Image) {
-	screen.Fill(color.RGBA{R: 5, G: 2, B: 2, A: 255})
+// leave closes the connection to the server, if any.
+func (g *Game) leave() {
+	if g.stop == nil {
+		return
+	}
+	close(g.stop)
+	g.stop = nil
+	g.disconnect()
 }
 
-func (g *Game) drawPauseScreen(screen *ebiten.Image) {
-	player := g.arena.player
-	str := player.GetName() + "(" + strconv.Itoa(player.GetScore()) + ")"
-	text.Draw(screen, str, mplusNormalFont, screenWidth/2-200, 96, color.Black)
+func (g *Game) reset() {
+	//g.match, _ = ai.NewRandomMatch(seed, ai.DefaultMatchConfig)
+}
+
+// drawDiamondMarkers draws a marker over every diamond so they stand out
+// while the player has a reveal effect.
+func (g *Game) drawDiamondMarkers(screen *ebiten.Image, view *model.View) {
+	marker := model.PickupColors[model.PickupReveal]
 
-	for i, player := range g.arena.remotePlayers {
-		str := player.GetName() + "(" + strconv.Itoa(player.GetScore()) + ")"
-		text.Draw(screen, str, mplusNormalFont, screenWidth/2-200, 64+(i+1)*96, color.Black)
+	for _, diamond := range g.match.Diamonds {
+		rect := diamond.Rect()
+		x := view.X(rect.Cx())
+		y := view.Y(rect.Top())
+		ebitenutil.DrawLine(screen, x, y-48, x, y-8, marker)
+		ebitenutil.DrawRect(screen, x-4, y-12, 8, 8, marker)
 	}
 }
 
-func (g *Game) watchRemainingTime() {
-	for {
-		select {
-		case <-time.After(1 * time.Second):
-			g.remainingTime -= time.Second
-		case <-g.quit:
-			return
+// drawEffects draws the effects the player has with the seconds they last.
+func (g *Game) drawEffects(screen *ebiten.Image) {
+	runner := g.arena.player.GetCharacter()
+	y := 176
+
+	for kind := model.PickupKind(0); kind < model.PickupKinds; kind++ {
+		if !runner.HasEffect(kind) {
+			continue
 		}
+		seconds := runner.EffectFrames(kind)/ebiten.MaxTPS() + 1
+		str := model.PickupNames[kind] + " " + strconv.Itoa(seconds)
+		text.Draw(screen, str, mplusSmallFont, screenWidth-200, y, model.PickupColors[kind])
+		y += 32
 	}
 }
 
-func Run() {
-	game := newGame()
+// drawPauseScreen draws the standings while the match is paused, the final
+// ones come from the server when the match ends.
+func (g *Game) drawPauseScreen(screen *ebiten.Image) {
+	players := g.arena.Players()
 
-	ebiten.SetWindowSize(screenWidth, screenHeight)
-	ebiten.SetWindowTitle("Dungeon MST")
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].GetScore() > players[j].GetScore()
+	})
+	rows := len(players)
 
-	go func() {
-		for {
-			select {
-			case game.quit <- true:
-			default:
-			}
-			m := <-game.matchCh
+	if rows > resultsMaxRows {
+		rows = resultsMaxRows
+	}
+	ebitenutil.DrawRect(screen, screenWidth/2-240, 48, 480, float64(96+rows*36), color.RGBA{A: 192})
+	text.Draw(screen, "Scoreboard", mplusSmallFont, screenWidth/2-200, 96, color.White)
 
-			game.SetMatch(m.Match)
-			game.remainingTime = m.RemainingTime
+	for i, player := range players[:rows] {
+		clr := color.Color(color.White)
 
-			for _, player := range m.Players {
-				game.arena.PushRemotePlayer(player.Id, player.Name, player.Score)
-			}
-			go game.watchRemainingTime()
+		if player == g.arena.player {
+			clr = resultsHighlight
 		}
-	}()
-	go func() {
-		for {
-			u := <-game.updateCh
+		str := strconv.Itoa(i+1) + ". " + player.GetName()
+		text.Draw(screen, str, mplusSmallFont, screenWidth/2-200, 144+i*36, clr)
+		text.Draw(screen, strconv.Itoa(player.GetScore()), mplusSmallFont, screenWidth/2+120, 144+i*36, clr)
+	}
+}
 
-			if u.Id == user.Id {
-				continue
-			}
-			//log.Println("Receiving update for player:", u.Id)
-			game.arena.SetRemotePlayerPosition(u.Id, u.PointJSON.ToPoint())
+// connectTo joins the server of the given config as the player it names, in
+// the background so the game shows how it's going.
+func (g *Game) connectTo(value config.Config) {
+	arena := NewArena(value.Name, g.keys)
+	g.arena = &arena
+	g.arena.SetOnCharacterMotion(g.onCharacterMotion)
+	g.stop = make(chan struct{})
+	user.Name = value.Name
 
-			if u.DiamondIndex != -1 {
-				game.match.Diamonds = remove(game.match.Diamonds, u.DiamondIndex)
+	go g.keepConnected(value, g.stop)
+}
 
-				game.arena.SetRemotePlayerScore(u.Id)
-			}
+// matchScene is the match being played.
+type matchScene struct {
+	game *Game
+}
 
-			//game.arena.PushRemotePlayerInput(u.Id, u.Move)
-		}
-	}()
-	go func() {
-		for {
-			j := <-game.joinCh
+func (s *matchScene) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		s.game.leave()
+		s.game.setScene(newMenuScene(s.game))
+		return nil
+	}
+	err := s.game.updateMatch()
 
-			if j.Id == user.Id {
-				continue
-			}
-			log.Println("Joining player:", j.Id)
-			game.arena.PushRemotePlayer(j.Id, j.Name, 0)
-		}
-	}()
+	if s.game.match != nil {
+		s.game.camera.Update(s.game.arena.player.GetCharacter().Rect, worldRect(s.game.match))
+	}
+	return err
+}
 
-	go func() {
-		for {
-			lid := <-game.leaveCh
+func (s *matchScene) Draw(screen *ebiten.Image) {
+	s.game.drawMatch(screen)
+}
 
-			game.arena.RemoveRemotePlayer(lid)
-		}
-	}()
+func newMatchScene(game *Game) *matchScene {
+	return &matchScene{game: game}
+}
+
+func Run() {
+	value, path, found := loadConfig()
+	keys, err := parseKeyBindings(value.Keys)
+
+	if err != nil {
+		log.Fatal("Invalid key bindings: ", err)
+	}
+	game := newGame(keys, value, path)
+
+	// The player sets up the game the first time, or fixes what's wrong
+	if !found || value.Validate() != nil {
+		game.setScene(newSettingsScene(&game))
+	} else {
+		game.setScene(newMenuScene(&game))
+	}
+
+	ebiten.SetWindowSize(screenWidth, screenHeight)
+	ebiten.SetWindowTitle("Dungeon MST")
 
-	if err := ebiten.RunGame(&game); err != nil {
+	if err := ebiten.RunGame(&game); err != nil && err != errQuit {
 		log.Fatal(err)
 	}
 }
 
-func newGame() Game {
-	arena := NewArena(user.Name)
+func newGame(keys []keyBinding, value config.Config, path string) Game {
 	legendImage := loadLegendImage()
+	arena := NewArena(value.Name, keys)
 	game := Game{
-		arena:       &arena,
-		legendImage: legendImage,
+		arena:        &arena,
+		legendImage:  legendImage,
+		camera:       newCamera(),
+		keys:         keys,
+		config:       value,
+		configPath:   path,
+		status:       &connectionStatus{},
+		disconnectCh: make(chan struct{}, 1),
+		acceptedCh:   make(chan *client.JoinAccepted),
 	}
 
-	game.arena.SetOnCharacterMotion(game.onCharacterMotion)
-
 	matchCh := make(chan *client.MatchInit)
 	updateCh := make(chan *client.Update)
 	sendUpdateCh := make(chan *client.Update)
 	joinCh := make(chan *client.PlayerJoin)
 	leaveCh := make(chan int)
-	quit := make(chan bool)
+	readyCh := make(chan int)
+	spawnCh := make(chan *client.DiamondSpawn)
+	pickupCh := make(chan *client.PickupSpawn)
+	expiredCh := make(chan *client.EffectExpired)
+	matchEndCh := make(chan *client.MatchEnd)
 
 	game.matchCh = matchCh
 	game.updateCh = updateCh
 	game.sendUpdateCh = sendUpdateCh
 	game.joinCh = joinCh
 	game.leaveCh = leaveCh
-	game.quit = quit
-
-	acceptedCh := make(chan *client.JoinAccepted)
-
-	go client.Run(user.Name, acceptedCh, matchCh, updateCh, sendUpdateCh, joinCh, leaveCh)
-
-	accepted := <-acceptedCh
-	arena.player.Id = accepted.Id
-	user.Id = accepted.Id
-
-	log.Println("Accepted", accepted.Id)
+	game.readyCh = readyCh
+	game.spawnCh = spawnCh
+	game.pickupCh = pickupCh
+	game.expiredCh = expiredCh
+	game.matchEndCh = matchEndCh
 	return game
 }
 
+// verifyMatch logs whether the given layout is not the one its seed
+// generates, it only warns since the match is being played already. It takes
+// the layout alone since the diamonds and pickups change meanwhile.
+func verifyMatch(seed int64, config ai.MatchConfig, layout *model.Match) {
+	if !ai.VerifyMatch(layout, seed, config) {
+		log.Println("Received match does not match its seed", seed)
+	}
+}
+
 func getSize() model.Dimension {
 	return model.NewDimension(screenWidth, screenHeight)
 }
 
 func init() {
 	loadBg()
-	loadUser()
 }
 
 func loadBg() {
@@ -328,16 +616,27 @@ func loadBg() {
 	bgImage = bgImg
 }
 
-func loadUser() {
-	content, err := ioutil.ReadFile("../user.json")
+// loadConfig returns the config of the game, from the config file overridden
+// by the environment and the command line, and the path of the file. It
+// returns false if there's no file yet.
+func loadConfig() (config.Config, string, bool) {
+	path, err := config.Path()
 
 	if err != nil {
-		log.Fatal("Failed to read user")
+		log.Fatal("Failed to find the config dir: ", err)
 	}
+	value, found, err := config.Load(path)
 
-	if err := json.Unmarshal(content, &user); err != nil {
-		log.Fatal("Failed to parse user")
+	if err != nil {
+		log.Fatal("Failed to read config: ", err)
 	}
+	if err := config.ApplyEnv(&value, os.LookupEnv); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.ApplyFlags(&value, os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+	return value, path, found
 }
 
 func loadLegendImage() *ebiten.Image {
@@ -353,9 +652,19 @@ func remove(slice []*model.Diamond, s int) []*model.Diamond {
 	return append(slice[:s], slice[s+1:]...)
 }
 
+func removePickup(slice []*model.Pickup, s int) []*model.Pickup {
+	return append(slice[:s], slice[s+1:]...)
+}
+
+// toFrames returns the number of game ticks the given duration lasts.
+func toFrames(duration time.Duration) int {
+	return int(duration.Seconds() * float64(ebiten.MaxTPS()))
+}
+
 // Place this here for now
 var (
 	mplusNormalFont font.Face
+	mplusSmallFont  font.Face
 )
 
 func init() {
@@ -373,4 +682,12 @@ func init() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	mplusSmallFont, err = opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    24,
+		DPI:     dpi,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 }
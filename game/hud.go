@@ -0,0 +1,200 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package main
+
+import (
+	"game/model"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// hudScoreboardRows is how many of the leading players the scoreboard
+	// lists, the player is added at the bottom if it's not among them.
+	hudScoreboardRows = 5
+
+	hudMargin    = 16
+	minimapWidth = 224
+)
+
+var (
+	hudBackground     = color.RGBA{A: 160}
+	minimapDungeon    = color.RGBA{R: 120, G: 110, B: 100, A: 255}
+	minimapPath       = color.RGBA{R: 80, G: 75, B: 70, A: 255}
+	minimapPlayer     = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	minimapRemote     = color.RGBA{R: 230, G: 90, B: 70, A: 255}
+	minimapBackground = color.RGBA{R: 10, G: 8, B: 8, A: 200}
+	minimapView       = color.RGBA{R: 255, G: 255, B: 255, A: 160}
+)
+
+// hud draws what the player needs to know over the match: the time and the
+// diamonds left, the effects, a live scoreboard and a minimap.
+type hud struct {
+	minimap *ebiten.Image
+	match   *model.Match
+	scale   float64
+}
+
+func (h *hud) Draw(screen *ebiten.Image, g *Game) {
+	seconds := int64(g.remainingTime / time.Second)
+
+	if seconds < 0 {
+		seconds = 0
+	}
+	text.Draw(screen, strconv.FormatInt(seconds, 10), mplusNormalFont, screenWidth-200, 96, color.White)
+
+	diamonds := strconv.Itoa(len(g.match.Diamonds)) + " diamonds left"
+	text.Draw(screen, diamonds, mplusSmallFont, screenWidth-200, 136, color.White)
+
+	g.drawEffects(screen)
+	h.drawScoreboard(screen, g.arena)
+	h.drawMinimap(screen, g.match, g.arena, g.camera.View())
+}
+
+// drawScoreboard draws the leading players below the keyboard legend, from
+// the highest score.
+func (h *hud) drawScoreboard(screen *ebiten.Image, arena *Arena) {
+	players := arena.Players()
+
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].GetScore() > players[j].GetScore()
+	})
+	var rows []string
+	local := -1
+
+	for i, player := range players {
+		if i < hudScoreboardRows || player == arena.player {
+			if player == arena.player {
+				local = len(rows)
+			}
+			rows = append(rows, strconv.Itoa(i+1)+". "+player.GetName()+"  "+strconv.Itoa(player.GetScore()))
+		}
+	}
+	x := hudMargin
+	y := 112 + hudMargin
+	ebitenutil.DrawRect(screen, float64(x), float64(y), 240, float64(8+len(rows)*28), hudBackground)
+
+	for i, row := range rows {
+		clr := color.Color(color.White)
+
+		if i == local {
+			clr = resultsHighlight
+		}
+		text.Draw(screen, row, mplusSmallFont, x+8, y+28+i*28, clr)
+	}
+}
+
+// drawMinimap draws the layout of the match at the bottom right, with the
+// diamonds, the players and the part of the world on the screen over it.
+func (h *hud) drawMinimap(screen *ebiten.Image, match *model.Match, arena *Arena, view model.View) {
+	if h.match != match {
+		h.renderMinimap(match)
+	}
+	size := h.minimap.Bounds().Size()
+	x := float64(screenWidth - hudMargin - size.X)
+	y := float64(screenHeight - hudMargin - size.Y)
+	op := &ebiten.DrawImageOptions{}
+
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(h.minimap, op)
+
+	for _, diamond := range match.Diamonds {
+		rect := diamond.Rect()
+		cx := x + float64(rect.Cx())*h.scale
+		cy := y + float64(rect.Cy())*h.scale
+		ebitenutil.DrawRect(screen, cx-2, cy-2, 4, 4, diamond.MarkerColor())
+	}
+	for _, player := range arena.remotePlayers {
+		h.drawDot(screen, x, y, player, minimapRemote)
+	}
+	h.drawDot(screen, x, y, arena.player, minimapPlayer)
+	h.drawView(screen, x, y, size, view)
+}
+
+// drawView outlines the view of the camera, within the minimap.
+func (h *hud) drawView(screen *ebiten.Image, x float64, y float64, size image.Point, view model.View) {
+	rect := view.Rect()
+	left := math.Max(x+float64(rect.Left())*h.scale, x)
+	top := math.Max(y+float64(rect.Top())*h.scale, y)
+	right := math.Min(x+float64(rect.Right())*h.scale, x+float64(size.X))
+	bottom := math.Min(y+float64(rect.Bottom())*h.scale, y+float64(size.Y))
+
+	ebitenutil.DrawLine(screen, left, top, right, top, minimapView)
+	ebitenutil.DrawLine(screen, left, bottom, right, bottom, minimapView)
+	ebitenutil.DrawLine(screen, left, top, left, bottom, minimapView)
+	ebitenutil.DrawLine(screen, right, top, right, bottom, minimapView)
+}
+
+func (h *hud) drawDot(screen *ebiten.Image, x float64, y float64, player *model.Player, clr color.Color) {
+	rect := player.GetCharacter().Rect
+	cx := x + float64(rect.Cx())*h.scale
+	cy := y + float64(rect.Cy())*h.scale
+	ebitenutil.DrawRect(screen, cx-3, cy-3, 6, 6, clr)
+}
+
+// renderMinimap draws the dungeons and paths of the match, which never
+// change, to the minimap image.
+func (h *hud) renderMinimap(match *model.Match) {
+	world := worldRect(match)
+
+	if h.minimap != nil {
+		h.minimap.Dispose()
+	}
+	h.match = match
+	h.scale = float64(minimapWidth) / float64(world.Width())
+	h.minimap = ebiten.NewImage(minimapWidth, int(float64(world.Height())*h.scale)+1)
+	h.minimap.Fill(minimapBackground)
+
+	for _, path := range match.Paths {
+		for _, line := range path.Lines() {
+			p1 := line.P1()
+			p2 := line.P2()
+			left := float64(min(p1.X(), p2.X())-model.PathWidthPx/2) * h.scale
+			top := float64(min(p1.Y(), p2.Y())-model.PathWidthPx/2) * h.scale
+			lineWidth := float64(abs(p1.X()-p2.X())+model.PathWidthPx) * h.scale
+			lineHeight := float64(abs(p1.Y()-p2.Y())+model.PathWidthPx) * h.scale
+			ebitenutil.DrawRect(h.minimap, left, top, lineWidth, lineHeight, minimapPath)
+		}
+	}
+	for _, dungeon := range match.Dungeons {
+		rect := dungeon.Rect()
+		ebitenutil.DrawRect(
+			h.minimap,
+			float64(rect.Left())*h.scale,
+			float64(rect.Top())*h.scale,
+			float64(rect.Width())*h.scale,
+			float64(rect.Height())*h.scale,
+			minimapDungeon,
+		)
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
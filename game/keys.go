@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package main
+
+import (
+	"fmt"
+	"game/config"
+	"game/model"
+	"github.com/hajimehoshi/ebiten/v2"
+	"strings"
+)
+
+// keyBinding is a key that moves the runner towards its direction.
+type keyBinding struct {
+	key       ebiten.Key
+	direction int
+}
+
+// parseKeyBindings returns the bindings of the config, in the order of the
+// ebiten keys so pressing many of them always moves the runner alike.
+func parseKeyBindings(keys config.KeyBindings) ([]keyBinding, error) {
+	directions := map[int][]string{
+		model.MoveDirTop:    keys.Up,
+		model.MoveDirBottom: keys.Down,
+		model.MoveDirLeft:   keys.Left,
+		model.MoveDirRight:  keys.Right,
+	}
+	bound := map[ebiten.Key]int{}
+
+	for direction, names := range directions {
+		for _, name := range names {
+			key, ok := parseKey(name)
+
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown key %q", config.ErrInvalid, name)
+			}
+			if other, ok := bound[key]; ok && other != direction {
+				return nil, fmt.Errorf("%w: key %q moves towards two directions", config.ErrInvalid, name)
+			}
+			bound[key] = direction
+		}
+	}
+	var bindings []keyBinding
+
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if direction, ok := bound[k]; ok {
+			bindings = append(bindings, keyBinding{k, direction})
+		}
+	}
+	return bindings, nil
+}
+
+func parseKey(name string) (ebiten.Key, bool) {
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if strings.EqualFold(k.String(), name) {
+			return k, true
+		}
+	}
+	return 0, false
+}
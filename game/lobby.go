@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"image/color"
+	"strconv"
+	"time"
+)
+
+// lobbyMaxRows is the most players the lobby lists.
+const lobbyMaxRows = 8
+
+// lobbyScene shows how the connection is going and, once the server sends
+// the match, the players in it until the player is ready to join them.
+type lobbyScene struct {
+	game *Game
+}
+
+func (s *lobbyScene) Update() error {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		s.game.leave()
+		s.game.setScene(newMenuScene(s.game))
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) && s.game.match != nil:
+		s.game.arena.player.SetReady(true)
+		s.game.setScene(newMatchScene(s.game))
+	}
+	return nil
+}
+
+func (s *lobbyScene) Draw(screen *ebiten.Image) {
+	drawTitle(screen, "Lobby")
+
+	if s.game.match == nil {
+		s.drawStatus(screen)
+		drawHint(screen, "Esc to leave")
+		return
+	}
+	seconds := int(s.game.remainingTime / time.Second)
+	str := "The match ends in " + strconv.Itoa(seconds) + "s"
+
+	// The server is between matches
+	if seconds <= 0 {
+		str = "The next match is about to start"
+	}
+	text.Draw(screen, str, mplusSmallFont, 96, 208, color.White)
+
+	players := s.game.arena.Players()
+
+	for i, player := range players {
+		if i == lobbyMaxRows {
+			more := "and " + strconv.Itoa(len(players)-i) + " more"
+			text.Draw(screen, more, mplusSmallFont, 96, 272+i*40, sceneHintColor)
+			break
+		}
+		row := player.GetName()
+
+		if player.IsBot() {
+			row += " (bot)"
+		}
+		if player.IsReady() {
+			row += "   playing, " + strconv.Itoa(player.GetScore()) + " points"
+		} else {
+			row += "   in the lobby"
+		}
+		text.Draw(screen, row, mplusSmallFont, 96, 272+i*40, color.White)
+	}
+	drawHint(screen, "Enter when ready, Esc to leave")
+}
+
+// drawStatus draws the status of the connection until the match arrives.
+func (s *lobbyScene) drawStatus(screen *ebiten.Image) {
+	message, retry, err := s.game.status.Get()
+	text.Draw(screen, message, mplusSmallFont, 96, 240, color.White)
+
+	if err != nil {
+		text.Draw(screen, err.Error(), mplusSmallFont, 96, 288, sceneErrorColor)
+		text.Draw(screen, retry, mplusSmallFont, 96, 336, sceneHintColor)
+	}
+}
+
+func newLobbyScene(game *Game) *lobbyScene {
+	return &lobbyScene{game: game}
+}
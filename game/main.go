@@ -13,9 +13,10 @@ import (
 // version is not stable.
 
 // Build the server from the server module and run it. (Set up the address).
-// Set the address also in the client package of the game module.
-// Open the file user.json at the root of this project to set your username.
-// Build the game from the game module and run it.
+// Build the game from the game module and run it, the first time it asks for
+// your username and the server address and saves them to the config dir.
+// Flags -addr, -tls and -name, or the DUNGEON_MST_* env variables, override
+// them.
 
 // The server generates random matches each x seconds. Just open your game.
 
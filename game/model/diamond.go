@@ -6,6 +6,9 @@ package model
 
 import (
 	"github.com/hajimehoshi/ebiten/v2"
+	"image/color"
+	"math"
+	"time"
 )
 
 const (
@@ -13,11 +16,60 @@ const (
 	DiamondHeightPx = 26
 )
 
+const (
+	DiamondCommon    DiamondKind = 0
+	DiamondRare      DiamondKind = 1
+	DiamondCursed    DiamondKind = 2
+	DiamondTimeBonus DiamondKind = 3
+)
+
+// DiamondKinds is the number of kinds of diamonds.
+const DiamondKinds = 4
+
+// DiamondValues is the scoring table, what a runner gets for picking up each
+// kind of diamond.
+var DiamondValues = [DiamondKinds]DiamondValue{
+	DiamondCommon:    {Points: 30},
+	DiamondRare:      {Points: 100},
+	DiamondCursed:    {Points: -50},
+	DiamondTimeBonus: {Points: 10, Time: 5 * time.Second},
+}
+
+// diamondTints are the RGB factors each kind of diamond is drawn with.
+var diamondTints = [DiamondKinds][3]float64{
+	DiamondCommon:    {1, 1, 1},
+	DiamondRare:      {1.4, 1.1, 0.3},
+	DiamondCursed:    {0.6, 0.2, 0.7},
+	DiamondTimeBonus: {0.4, 1.3, 0.5},
+}
+
+type DiamondKind int
+
+// DiamondValue is the score a diamond adds and the time it extends the
+// match by.
+type DiamondValue struct {
+	Points int
+	Time   time.Duration
+}
+
 type Diamond struct {
 	rect  Rect
+	kind  DiamondKind
 	image *ebiten.Image
 }
 
+func (d *Diamond) Rect() Rect {
+	return d.rect
+}
+
+func (d *Diamond) Kind() DiamondKind {
+	return d.kind
+}
+
+func (d *Diamond) Value() DiamondValue {
+	return DiamondValues[d.kind]
+}
+
 func (d *Diamond) Collides(rect *Rect) bool {
 	return d.rect.Intersects(rect)
 }
@@ -26,14 +78,33 @@ func (d *Diamond) Update() {
 
 }
 
-func (d *Diamond) Draw(screen *ebiten.Image) {
+// MarkerColor returns the color the minimap marks the diamond with, tinted
+// like the diamond.
+func (d *Diamond) MarkerColor() color.RGBA {
+	tint := diamondTints[d.kind]
+	scale := func(value float64) uint8 {
+		return uint8(math.Min(value, 255))
+	}
+	return color.RGBA{R: scale(200 * tint[0]), G: scale(220 * tint[1]), B: scale(255 * tint[2]), A: 255}
+}
+
+func (d *Diamond) Draw(screen *ebiten.Image, view *View) {
 	op := &ebiten.DrawImageOptions{}
+	tint := diamondTints[d.kind]
 
-	op.GeoM.Translate(float64(d.rect.Left()), float64(d.rect.Top()))
+	op.ColorM.Scale(tint[0], tint[1], tint[2], 1)
+	op.GeoM.Translate(view.X(d.rect.Left()), view.Y(d.rect.Top()))
 	screen.DrawImage(d.image, op)
 }
 
 func NewDiamond(point Point) Diamond {
+	return NewDiamondOfKind(point, DiamondCommon)
+}
+
+func NewDiamondOfKind(point Point, kind DiamondKind) Diamond {
+	if kind < 0 || kind >= DiamondKinds {
+		panic("Invalid diamond kind")
+	}
 	rect := Rect{
 		left:   point.X(),
 		top:    point.Y(),
@@ -43,20 +114,22 @@ func NewDiamond(point Point) Diamond {
 	image := NewImageFromAssets("diamond.png")
 	return Diamond{
 		rect:  rect,
+		kind:  kind,
 		image: image,
 	}
 }
 
 type DiamondJSON struct {
 	*PointJSON
+	Kind DiamondKind
 }
 
 func (d *DiamondJSON) ToDiamond() *Diamond {
-	diamond := NewDiamond(*d.PointJSON.ToPoint())
+	diamond := NewDiamondOfKind(*d.PointJSON.ToPoint(), d.Kind)
 	return &diamond
 }
 
 func NewDiamondJSON(d *Diamond) *DiamondJSON {
 	point := &Point{d.rect.left, d.rect.top}
-	return &DiamondJSON{NewPointJSON(point)}
+	return &DiamondJSON{NewPointJSON(point), d.kind}
 }
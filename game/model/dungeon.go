@@ -28,6 +28,11 @@ var (
 type Dungeon struct {
 	rect    Rect
 	barrier Barrier
+	hazards []*Hazard
+}
+
+func (d *Dungeon) Rect() Rect {
+	return d.rect
 }
 
 func (d *Dungeon) Width() int {
@@ -50,25 +55,19 @@ func (d *Dungeon) Center() Point {
 	return NewPoint(d.Cx(), d.Cy())
 }
 
+// GetPathFor returns the path from the center of this dungeon to the center
+// of the given one, going vertically first and then horizontally.
 func (d *Dungeon) GetPathFor(dungeon *Dungeon) *Path {
 	center := d.Center()
-	hp1x := min(center.X(), dungeon.Cx())
-	hp2x := max(center.X(), dungeon.Cx())
-	hpy := dungeon.Cy()
-	hl := Line{
-		p1: Point{hp1x, hpy},
-		p2: Point{hp2x, hpy},
-	}
+	elbow := NewPoint(d.Cx(), dungeon.Cy())
+	target := dungeon.Center()
+	points := []Point{center}
 
-	vp1x := center.X()
-	vp1y := min(center.Y(), dungeon.Cy())
-	vp2y := max(center.Y(), dungeon.Cy())
-	vl := Line{
-		p1: Point{vp1x, vp1y},
-		p2: Point{vp1x, vp2y},
+	if !elbow.Equals(&center) && !elbow.Equals(&target) {
+		points = append(points, elbow)
 	}
-
-	path := NewPath(hl, vl)
+	points = append(points, target)
+	path := NewPath(points)
 	return &path
 }
 
@@ -76,10 +75,42 @@ func (d *Dungeon) Intersects(rect *Rect) bool {
 	return d.rect.Intersects(rect)
 }
 
+func (d *Dungeon) IntersectsPath(path *Path) bool {
+	return path.Intersects(&d.rect)
+}
+
+func (d *Dungeon) Contains(point *Point) bool {
+	return d.rect.Contains(point)
+}
+
 func (d *Dungeon) InBounds(rect *Rect) bool {
 	return d.rect.InBounds(rect)
 }
 
+// AddDoor opens a door on each wall the given path crosses.
+func (d *Dungeon) AddDoor(path *Path) {
+	d.barrier.addDoors(path)
+}
+
+func (d *Dungeon) Hazards() []*Hazard {
+	return d.hazards
+}
+
+func (d *Dungeon) AddHazard(hazard Hazard) {
+	d.hazards = append(d.hazards, &hazard)
+}
+
+// HazardAt returns the hazard the given rect stands on, or nil if it's on
+// plain floor.
+func (d *Dungeon) HazardAt(rect *Rect) *Hazard {
+	for _, hazard := range d.hazards {
+		if hazard.IsUnder(rect) {
+			return hazard
+		}
+	}
+	return nil
+}
+
 func (d *Dungeon) CanMoveTowards(movement Movement, rect *Rect) bool {
 	if !d.InBounds(rect) {
 		return true
@@ -87,24 +118,28 @@ func (d *Dungeon) CanMoveTowards(movement Movement, rect *Rect) bool {
 	return !d.barrier.WillCollide(movement, rect)
 }
 
-func (d *Dungeon) DrawBarrier(screen *ebiten.Image) {
-	d.barrier.Draw(screen)
+func (d *Dungeon) DrawBarrier(screen *ebiten.Image, view *View) {
+	d.barrier.Draw(screen, view)
 }
 
-func (d *Dungeon) Draw(screen *ebiten.Image) {
+func (d *Dungeon) Draw(screen *ebiten.Image, view *View) {
 	op := &ebiten.DrawImageOptions{}
 
 	// Draw Background
 	rect := image.Rect(0, 0, d.rect.Width()-2*wallWidth, d.rect.Height()-2*wallWidth)
 
 	op.GeoM.Reset()
-	op.GeoM.Translate(float64(d.rect.Left()+wallWidth), float64(d.rect.Top()+wallWidth))
+	op.GeoM.Translate(view.X(d.rect.Left()+wallWidth), view.Y(d.rect.Top()+wallWidth))
 	screen.DrawImage(bgImage.SubImage(rect).(*ebiten.Image), op)
+
+	for _, hazard := range d.hazards {
+		hazard.Draw(screen, view)
+	}
 }
 
-func (d *Dungeon) RandomPoint(p int) Point {
-	x := rand.Intn(d.Width()-wallWidth*2-p) + d.rect.Left() + wallWidth
-	y := rand.Intn(d.Height()-wallWidth*2-p) + d.rect.Top() + wallWidth
+func (d *Dungeon) RandomPoint(rng *rand.Rand, p int) Point {
+	x := rng.Intn(d.Width()-wallWidth*2-p) + d.rect.Left() + wallWidth
+	y := rng.Intn(d.Height()-wallWidth*2-p) + d.rect.Top() + wallWidth
 	return Point{x, y}
 }
 
@@ -116,14 +151,15 @@ func NewDungeon(p0 Point, factor DimensionFactor) Dungeon {
 	rect := NewRect(x0, y0, x0+w, y0+h)
 	barrier := NewBarrier(rect, factor)
 	return Dungeon{
-		rect,
-		barrier,
+		rect:    rect,
+		barrier: barrier,
 	}
 }
 
 type DungeonJSON struct {
 	*RectJSON
 	*BarrierJSON
+	HazardsJSON []*HazardJSON
 }
 
 func (d *DungeonJSON) ToDungeon() *Dungeon {
@@ -131,15 +167,26 @@ func (d *DungeonJSON) ToDungeon() *Dungeon {
 		NewPoint(d.RectJSON.Left, d.RectJSON.Top),
 		*d.BarrierJSON.Factor,
 	)
+	dungeon.barrier.doors = d.BarrierJSON.ToBarrier().doors
+
+	for _, hazardJSON := range d.HazardsJSON {
+		dungeon.hazards = append(dungeon.hazards, hazardJSON.ToHazard())
+	}
 	return &dungeon
 }
 
 func NewDungeonJSON(d *Dungeon) *DungeonJSON {
 	rect := NewRectJSON(&d.rect)
 	barrier := NewBarrierJSON(&d.barrier)
+	var hazardsJSON []*HazardJSON
+
+	for _, hazard := range d.hazards {
+		hazardsJSON = append(hazardsJSON, NewHazardJSON(hazard))
+	}
 	return &DungeonJSON{
 		RectJSON:    rect,
 		BarrierJSON: barrier,
+		HazardsJSON: hazardsJSON,
 	}
 }
 
@@ -166,57 +213,130 @@ func NewWallJSON(w *Wall) *WallJSON {
 	return &WallJSON{NewRectJSON(&w.rect)}
 }
 
+// Door is an opening in a wall where a path meets the dungeon.
+type Door struct {
+	rect Rect
+}
+
+func (d *Door) Rect() Rect {
+	return d.rect
+}
+
+type DoorJSON struct {
+	*RectJSON
+}
+
+func (d *DoorJSON) ToDoor() *Door {
+	return &Door{*d.RectJSON.ToRect()}
+}
+
+func NewDoorJSON(d *Door) *DoorJSON {
+	return &DoorJSON{NewRectJSON(&d.rect)}
+}
+
 type Barrier struct {
 	factor     DimensionFactor
 	leftWall   Wall
 	topWall    Wall
 	rightWall  Wall
 	bottomWall Wall
+	doors      []Door
 }
 
-func (b *Barrier) WillCollide(movement Movement, objRect *Rect) bool {
-	return WillCollide(movement, &b.leftWall.rect, objRect) ||
-		WillCollide(movement, &b.topWall.rect, objRect) ||
-		WillCollide(movement, &b.rightWall.rect, objRect) ||
-		WillCollide(movement, &b.bottomWall.rect, objRect)
+func (b *Barrier) Doors() []Door {
+	return b.doors
 }
 
-func (b *Barrier) Draw(screen *ebiten.Image) {
-	op := &ebiten.DrawImageOptions{}
-	wFactor := b.factor.Width
-	hFactor := b.factor.Height
-	blockWidth := horizontalUnitHeightPx
+// WillCollide tells whether the rect hits a wall after the movement, the
+// parts of the walls taken by doors are open.
+func (b *Barrier) WillCollide(movement Movement, objRect *Rect) bool {
+	dst := Move(objRect, movement)
 
-	// Draw Top
-	op.GeoM.Reset()
-	op.GeoM.Translate(float64(b.topWall.rect.Left()), float64(b.topWall.rect.Top()))
-	for i := 0; i < wFactor; i++ {
-		screen.DrawImage(brickImage, op)
-		op.GeoM.Translate(horizontalUnitWidthPx, 0)
+	for _, wall := range b.walls() {
+		if wall.rect.Intersects(dst) && !b.isThroughDoor(&wall.rect, dst) {
+			return true
+		}
 	}
+	return false
+}
+
+// isThroughDoor tells whether the part of the wall touched by the rect lies
+// within a door.
+func (b *Barrier) isThroughDoor(wall *Rect, rect *Rect) bool {
+	left := max(wall.Left(), rect.Left())
+	top := max(wall.Top(), rect.Top())
+	right := min(wall.Right(), rect.Right())
+	bottom := min(wall.Bottom(), rect.Bottom())
+
+	for _, door := range b.doors {
+		if door.rect.Left() <= left && door.rect.Top() <= top &&
+			door.rect.Right() >= right && door.rect.Bottom() >= bottom {
+			return true
+		}
+	}
+	return false
+}
 
-	// Draw Bottom
-	op.GeoM.Reset()
-	op.GeoM.Translate(float64(b.bottomWall.rect.Left()), float64(b.bottomWall.rect.Bottom()-blockWidth))
-	for i := 0; i < wFactor; i++ {
-		screen.DrawImage(brickImage, op)
-		op.GeoM.Translate(horizontalUnitWidthPx, 0)
+func (b *Barrier) addDoors(path *Path) {
+	for _, wall := range b.walls() {
+		for _, rect := range path.rects {
+			if overlap, ok := wall.rect.Overlap(&rect); ok {
+				b.doors = append(b.doors, Door{overlap})
+			}
+		}
 	}
+}
 
-	// Draw Left
-	op.GeoM.Reset()
-	op.GeoM.Translate(float64(b.leftWall.rect.Left()), float64(b.leftWall.rect.Top()))
-	for i := 0; i < hFactor; i++ {
-		screen.DrawImage(brickYImage, op)
-		op.GeoM.Translate(0, horizontalUnitWidthPx)
+func (b *Barrier) walls() []*Wall {
+	return []*Wall{&b.leftWall, &b.topWall, &b.rightWall, &b.bottomWall}
+}
+
+// Draw tiles the bricks of each wall, leaving a gap where each door is.
+func (b *Barrier) Draw(screen *ebiten.Image, view *View) {
+	b.drawWall(screen, view, &b.topWall, true)
+	b.drawWall(screen, view, &b.bottomWall, true)
+	b.drawWall(screen, view, &b.leftWall, false)
+	b.drawWall(screen, view, &b.rightWall, false)
+}
+
+func (b *Barrier) drawWall(screen *ebiten.Image, view *View, wall *Wall, horizontal bool) {
+	if !view.IsVisible(&wall.rect) {
+		return
 	}
+	op := &ebiten.DrawImageOptions{}
+	start := wall.rect.Top()
+	end := wall.rect.Bottom()
 
-	// Draw Right
-	op.GeoM.Reset()
-	op.GeoM.Translate(float64(b.rightWall.rect.Right()-blockWidth), float64(b.rightWall.rect.Top()))
-	for i := 0; i < hFactor; i++ {
-		screen.DrawImage(brickYImage, op)
-		op.GeoM.Translate(0, horizontalUnitWidthPx)
+	if horizontal {
+		start = wall.rect.Left()
+		end = wall.rect.Right()
+	}
+	for tile := start; tile < end; tile += horizontalUnitWidthPx {
+		spans := [][2]int{{tile, min(tile+horizontalUnitWidthPx, end)}}
+
+		for _, door := range b.doors {
+			if !wall.rect.InBounds(&door.rect) {
+				continue
+			}
+			if horizontal {
+				spans = cutSpans(spans, door.rect.Left(), door.rect.Right())
+			} else {
+				spans = cutSpans(spans, door.rect.Top(), door.rect.Bottom())
+			}
+		}
+		for _, span := range spans {
+			var src image.Rectangle
+			op.GeoM.Reset()
+
+			if horizontal {
+				src = image.Rect(span[0]-tile, 0, span[1]-tile, wallWidth)
+				op.GeoM.Translate(view.X(span[0]), view.Y(wall.rect.Top()))
+			} else {
+				src = image.Rect(0, span[0]-tile, wallWidth, span[1]-tile)
+				op.GeoM.Translate(view.X(wall.rect.Left()), view.Y(span[0]))
+			}
+			screen.DrawImage(wall.image.SubImage(src).(*ebiten.Image), op)
+		}
 	}
 }
 
@@ -268,6 +388,7 @@ type BarrierJSON struct {
 	TopWallJSON    *WallJSON
 	RightWallJSON  *WallJSON
 	BottomWallJSON *WallJSON
+	DoorsJSON      []*DoorJSON
 }
 
 func (b *BarrierJSON) ToBarrier() *Barrier {
@@ -279,16 +400,26 @@ func (b *BarrierJSON) ToBarrier() *Barrier {
 		b.BottomWallJSON.RectJSON.Bottom,
 	)
 	barrier := NewBarrier(rect, *factor)
+
+	for _, doorJSON := range b.DoorsJSON {
+		barrier.doors = append(barrier.doors, *doorJSON.ToDoor())
+	}
 	return &barrier
 }
 
 func NewBarrierJSON(b *Barrier) *BarrierJSON {
+	var doorsJSON []*DoorJSON
+
+	for i := range b.doors {
+		doorsJSON = append(doorsJSON, NewDoorJSON(&b.doors[i]))
+	}
 	return &BarrierJSON{
 		Factor:         &b.factor,
 		LeftWallJSON:   NewWallJSON(&b.leftWall),
 		TopWallJSON:    NewWallJSON(&b.topWall),
 		RightWallJSON:  NewWallJSON(&b.rightWall),
 		BottomWallJSON: NewWallJSON(&b.bottomWall),
+		DoorsJSON:      doorsJSON,
 	}
 }
 
@@ -319,6 +450,25 @@ func getBrickYImage() *ebiten.Image {
 	return brickYImg
 }
 
+// cutSpans removes the interval from lo to hi out of the given spans.
+func cutSpans(spans [][2]int, lo int, hi int) [][2]int {
+	var result [][2]int
+
+	for _, span := range spans {
+		if hi <= span[0] || lo >= span[1] {
+			result = append(result, span)
+			continue
+		}
+		if span[0] < lo {
+			result = append(result, [2]int{span[0], lo})
+		}
+		if hi < span[1] {
+			result = append(result, [2]int{hi, span[1]})
+		}
+	}
+	return result
+}
+
 func GetDungeonHorizontalUnitSize() Dimension {
 	return NewDimension(
 		horizontalUnitWidthPx,
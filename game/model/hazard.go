@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package model
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"image/color"
+)
+
+const (
+	HazardSizePx = 48
+
+	// SpikeStunFrames is how long spikes stun the runner stepping on them.
+	SpikeStunFrames = 60
+
+	// CollapseFrames is how long a runner can stand on a collapsing floor
+	// before it gives way.
+	CollapseFrames = 90
+
+	// FallStunFrames is how long a runner is stunned after falling through a
+	// collapsing floor.
+	FallStunFrames = 45
+)
+
+const (
+	HazardSpikes   HazardKind = 0
+	HazardSlow     HazardKind = 1
+	HazardTeleport HazardKind = 2
+	HazardCollapse HazardKind = 3
+)
+
+// HazardKinds is the number of kinds of hazards.
+const HazardKinds = 4
+
+// HazardColors are the colors each kind of hazard is drawn with.
+var HazardColors = [HazardKinds]color.RGBA{
+	HazardSpikes:   {R: 120, G: 120, B: 130, A: 255},
+	HazardSlow:     {R: 70, G: 110, B: 60, A: 160},
+	HazardTeleport: {R: 170, G: 80, B: 220, A: 255},
+	HazardCollapse: {R: 110, G: 75, B: 45, A: 255},
+}
+
+// HazardKind is what a hazard does to the runner standing on it: spikes stun
+// it, slow zones halve its speed, teleport pads send it to their target and
+// collapsing floors drop it back to the center of the dungeon if it stands on
+// them for too long.
+type HazardKind int
+
+// Hazard is a trap lying on the floor of a dungeon.
+type Hazard struct {
+	rect   Rect
+	kind   HazardKind
+	target Point
+}
+
+func (h *Hazard) Rect() Rect {
+	return h.rect
+}
+
+func (h *Hazard) Kind() HazardKind {
+	return h.kind
+}
+
+// Target returns the position a teleport pad sends runners to.
+func (h *Hazard) Target() Point {
+	return h.target
+}
+
+// IsUnder tells whether the runner rect stands on the hazard, that is its
+// center is within it.
+func (h *Hazard) IsUnder(rect *Rect) bool {
+	center := rect.Center()
+	return h.rect.Contains(&center)
+}
+
+// Draw draws the hazard as a tile of the color of its kind, with studs for
+// spikes, a frame for teleport pads and cracks for collapsing floors.
+func (h *Hazard) Draw(screen *ebiten.Image, view *View) {
+	x := view.X(h.rect.Left())
+	y := view.Y(h.rect.Top())
+	size := float64(HazardSizePx)
+	c := HazardColors[h.kind]
+
+	ebitenutil.DrawRect(screen, x, y, size, size, c)
+
+	switch h.kind {
+	case HazardSpikes:
+		for i := 0.0; i < 4; i++ {
+			for j := 0.0; j < 4; j++ {
+				ebitenutil.DrawRect(screen, x+4+i*12, y+4+j*12, 4, 4, color.White)
+			}
+		}
+	case HazardTeleport:
+		ebitenutil.DrawRect(screen, x+6, y+6, size-12, size-12, color.Black)
+		ebitenutil.DrawRect(screen, x+14, y+14, size-28, size-28, c)
+	case HazardCollapse:
+		ebitenutil.DrawLine(screen, x+8, y+6, x+size/2, y+size/2, color.Black)
+		ebitenutil.DrawLine(screen, x+size/2, y+size/2, x+size-6, y+14, color.Black)
+		ebitenutil.DrawLine(screen, x+size/2, y+size/2, x+18, y+size-6, color.Black)
+	}
+}
+
+func NewHazard(point Point, kind HazardKind, target Point) Hazard {
+	if kind < 0 || kind >= HazardKinds {
+		panic("Invalid hazard kind")
+	}
+	rect := Rect{
+		left:   point.X(),
+		top:    point.Y(),
+		right:  point.X() + HazardSizePx,
+		bottom: point.Y() + HazardSizePx,
+	}
+	return Hazard{
+		rect:   rect,
+		kind:   kind,
+		target: target,
+	}
+}
+
+type HazardJSON struct {
+	*PointJSON
+	Kind       HazardKind
+	TargetJSON *PointJSON
+}
+
+func (h *HazardJSON) ToHazard() *Hazard {
+	hazard := NewHazard(*h.PointJSON.ToPoint(), h.Kind, *h.TargetJSON.ToPoint())
+	return &hazard
+}
+
+func NewHazardJSON(h *Hazard) *HazardJSON {
+	point := &Point{h.rect.left, h.rect.top}
+	return &HazardJSON{NewPointJSON(point), h.kind, NewPointJSON(&h.target)}
+}
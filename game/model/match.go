@@ -4,22 +4,65 @@
 
 package model
 
+import "reflect"
+
 type Match struct {
 	Dungeons []*Dungeon
 	Paths    []*Path
 	Diamonds []*Diamond
+	Pickups  []*Pickup
+	nav      *Nav
+}
+
+// Nav returns the walkable graph of the match, built the first time it's
+// needed since the layout never changes.
+func (m *Match) Nav() *Nav {
+	if m.nav == nil {
+		m.nav = NewNav(m)
+	}
+	return m.nav
+}
+
+// HasSameLayout tells whether both matches have the same dungeons and paths.
+func (m *Match) HasSameLayout(match *Match) bool {
+	a := NewMatchJSON(m)
+	b := NewMatchJSON(match)
+	return reflect.DeepEqual(a.DungeonsJSON, b.DungeonsJSON) &&
+		reflect.DeepEqual(a.PathsJSON, b.PathsJSON)
+}
+
+// SetCurrentDungeonAndPaths sets the dungeon and paths the runner is within.
+func (m *Match) SetCurrentDungeonAndPaths(runner *Runner) {
+	var currentDungeon *Dungeon = nil
+	var currentPaths []*Path
+
+	for _, dungeon := range m.Dungeons {
+		if dungeon.InBounds(&runner.Rect) {
+			currentDungeon = dungeon
+			break
+		}
+	}
+	for _, path := range m.Paths {
+		if path.InBounds(&runner.Rect) {
+			currentPaths = append(currentPaths, path)
+		}
+	}
+	runner.SetCurrentDungeon(currentDungeon)
+	runner.SetCurrentPaths(currentPaths)
 }
 
 type MatchJSON struct {
 	DungeonsJSON []*DungeonJSON
 	PathsJSON    []*PathJSON
 	DiamondsJSON []*DiamondJSON
+	PickupsJSON  []*PickupJSON
 }
 
 func (m *MatchJSON) ToMatch() *Match {
 	var dungeons []*Dungeon
 	var paths []*Path
 	var diamonds []*Diamond
+	var pickups []*Pickup
 
 	for _, dungeonJSON := range m.DungeonsJSON {
 		dungeons = append(dungeons, dungeonJSON.ToDungeon())
@@ -32,10 +75,15 @@ func (m *MatchJSON) ToMatch() *Match {
 	for _, diamondJSON := range m.DiamondsJSON {
 		diamonds = append(diamonds, diamondJSON.ToDiamond())
 	}
+
+	for _, pickupJSON := range m.PickupsJSON {
+		pickups = append(pickups, pickupJSON.ToPickup())
+	}
 	return &Match{
 		Dungeons: dungeons,
 		Paths:    paths,
 		Diamonds: diamonds,
+		Pickups:  pickups,
 	}
 }
 
@@ -43,6 +91,7 @@ func NewMatchJSON(m *Match) *MatchJSON {
 	var dungeonsJSON []*DungeonJSON
 	var pathsJSON []*PathJSON
 	var diamondsJSON []*DiamondJSON
+	var pickupsJSON []*PickupJSON
 
 	for _, dungeon := range m.Dungeons {
 		dungeonsJSON = append(dungeonsJSON, NewDungeonJSON(dungeon))
@@ -55,9 +104,14 @@ func NewMatchJSON(m *Match) *MatchJSON {
 	for _, diamond := range m.Diamonds {
 		diamondsJSON = append(diamondsJSON, NewDiamondJSON(diamond))
 	}
+
+	for _, pickup := range m.Pickups {
+		pickupsJSON = append(pickupsJSON, NewPickupJSON(pickup))
+	}
 	return &MatchJSON{
 		DungeonsJSON: dungeonsJSON,
 		PathsJSON:    pathsJSON,
 		DiamondsJSON: diamondsJSON,
+		PickupsJSON:  pickupsJSON,
 	}
 }
@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package model
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"image/color"
+	"time"
+)
+
+const (
+	PickupSizePx = 24
+
+	// SpeedBoost is the number of steps a boosted runner walks per input.
+	SpeedBoost = 2
+
+	// MagnetRadiusPx is how far a runner with a magnet picks diamonds up from.
+	MagnetRadiusPx = 64
+)
+
+const (
+	PickupSpeed  PickupKind = 0
+	PickupShield PickupKind = 1
+	PickupMagnet PickupKind = 2
+	PickupReveal PickupKind = 3
+)
+
+// PickupKinds is the number of kinds of pickups.
+const PickupKinds = 4
+
+// PickupDurations is how long the effect of each kind of pickup lasts.
+var PickupDurations = [PickupKinds]time.Duration{
+	PickupSpeed:  5 * time.Second,
+	PickupShield: 8 * time.Second,
+	PickupMagnet: 6 * time.Second,
+	PickupReveal: 10 * time.Second,
+}
+
+// PickupColors are the colors each kind of pickup is drawn with, also used
+// by the HUD.
+var PickupColors = [PickupKinds]color.RGBA{
+	PickupSpeed:  {R: 250, G: 200, B: 40, A: 255},
+	PickupShield: {R: 60, G: 140, B: 250, A: 255},
+	PickupMagnet: {R: 230, G: 60, B: 60, A: 255},
+	PickupReveal: {R: 150, G: 240, B: 150, A: 255},
+}
+
+// PickupNames are the names the HUD shows for each kind of pickup.
+var PickupNames = [PickupKinds]string{
+	PickupSpeed:  "Speed",
+	PickupShield: "Shield",
+	PickupMagnet: "Magnet",
+	PickupReveal: "Reveal",
+}
+
+// PickupKind is the effect a pickup gives to the runner taking it: speed
+// makes it walk faster, shield keeps it from being stunned, magnet picks
+// diamonds up from further away and reveal shows where diamonds are.
+type PickupKind int
+
+// Pickup is a power-up lying on the map.
+type Pickup struct {
+	rect Rect
+	kind PickupKind
+}
+
+func (p *Pickup) Rect() Rect {
+	return p.rect
+}
+
+func (p *Pickup) Kind() PickupKind {
+	return p.kind
+}
+
+func (p *Pickup) Duration() time.Duration {
+	return PickupDurations[p.kind]
+}
+
+func (p *Pickup) Collides(rect *Rect) bool {
+	return p.rect.Intersects(rect)
+}
+
+// Draw draws the pickup as a framed square of the color of its kind.
+func (p *Pickup) Draw(screen *ebiten.Image, view *View) {
+	x := view.X(p.rect.Left())
+	y := view.Y(p.rect.Top())
+	size := float64(PickupSizePx)
+
+	ebitenutil.DrawRect(screen, x, y, size, size, color.White)
+	ebitenutil.DrawRect(screen, x+3, y+3, size-6, size-6, PickupColors[p.kind])
+}
+
+func NewPickup(point Point, kind PickupKind) Pickup {
+	if kind < 0 || kind >= PickupKinds {
+		panic("Invalid pickup kind")
+	}
+	rect := Rect{
+		left:   point.X(),
+		top:    point.Y(),
+		right:  point.X() + PickupSizePx,
+		bottom: point.Y() + PickupSizePx,
+	}
+	return Pickup{
+		rect: rect,
+		kind: kind,
+	}
+}
+
+type PickupJSON struct {
+	*PointJSON
+	Kind PickupKind
+}
+
+func (p *PickupJSON) ToPickup() *Pickup {
+	pickup := NewPickup(*p.PointJSON.ToPoint(), p.Kind)
+	return &pickup
+}
+
+func NewPickupJSON(p *Pickup) *PickupJSON {
+	point := &Point{p.rect.left, p.rect.top}
+	return &PickupJSON{NewPointJSON(point), p.kind}
+}
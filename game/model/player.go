@@ -6,13 +6,13 @@ package model
 
 import (
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
 	"image/color"
 	"log"
-	"strconv"
 )
 
 var (
@@ -36,11 +36,20 @@ func init() {
 	}
 }
 
+const (
+	nameTagMargin  = 6
+	nameTagPadding = 3
+)
+
+var nameTagBackground = color.RGBA{A: 160}
+
 type Player struct {
 	Id             int
 	name           string
 	character      *Runner
 	score          int
+	ready          bool
+	bot            bool
 	motionListener MotionListener
 }
 
@@ -56,6 +65,24 @@ func (p *Player) SetScore(value int) {
 	p.score = value
 }
 
+// IsReady tells whether the player left the lobby and is playing the match.
+func (p *Player) IsReady() bool {
+	return p.ready
+}
+
+func (p *Player) SetReady(value bool) {
+	p.ready = value
+}
+
+// IsBot tells whether the player is played by the server.
+func (p *Player) IsBot() bool {
+	return p.bot
+}
+
+func (p *Player) SetBot(value bool) {
+	p.bot = value
+}
+
 func (p *Player) GetCharacter() *Runner {
 	return p.character
 }
@@ -85,18 +112,30 @@ func (p *Player) Update() {
 	runner.Update()
 }
 
-func (p *Player) Draw(screen *ebiten.Image) {
-	p.character.Draw(screen)
-	p.drawName(screen)
+func (p *Player) Draw(screen *ebiten.Image, view *View) {
+	if !view.IsVisible(&p.character.Rect) {
+		return
+	}
+	p.character.Draw(screen, view)
+	p.drawName(screen, view)
 }
 
-func (p *Player) drawName(screen *ebiten.Image) {
-	name := p.name
-	str := name + "(" + strconv.Itoa(p.score) + ")"
-	character := p.character
-	x := character.Rect.Left()
-	y := character.Rect.Top()
-	text.Draw(screen, str, mplusNormalFont, x, y, color.Black)
+// drawName draws the name tag of the player centered above its runner.
+func (p *Player) drawName(screen *ebiten.Image, view *View) {
+	bounds := text.BoundString(mplusNormalFont, p.name)
+	rect := p.character.Rect
+	x := int(view.X(rect.Cx())) - bounds.Dx()/2
+	y := int(view.Y(rect.Top())) - nameTagMargin
+
+	ebitenutil.DrawRect(
+		screen,
+		float64(x+bounds.Min.X-nameTagPadding),
+		float64(y+bounds.Min.Y-nameTagPadding),
+		float64(bounds.Dx()+2*nameTagPadding),
+		float64(bounds.Dy()+2*nameTagPadding),
+		nameTagBackground,
+	)
+	text.Draw(screen, p.name, mplusNormalFont, x, y, color.White)
 }
 
 func NewPlayer(name string) Player {
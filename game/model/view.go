@@ -0,0 +1,39 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package model
+
+// View is the part of the world being drawn. Things are drawn translated so
+// the left-top corner of the view is at the origin of the image, and those
+// out of it are skipped.
+type View struct {
+	rect Rect
+}
+
+func (v *View) Rect() Rect {
+	return v.rect
+}
+
+// IsVisible tells whether any of the given rect is in the view.
+func (v *View) IsVisible(rect *Rect) bool {
+	return v.rect.Intersects(rect)
+}
+
+// X returns where the given world x is drawn.
+func (v *View) X(x int) float64 {
+	return float64(x - v.rect.left)
+}
+
+// Y returns where the given world y is drawn.
+func (v *View) Y(y int) float64 {
+	return float64(y - v.rect.top)
+}
+
+// NewView returns the view of the given size from the given left-top corner,
+// which can be out of the world when the world is smaller than the view.
+func NewView(left int, top int, width int, height int) View {
+	return View{
+		rect: Rect{left, top, left + width, top + height},
+	}
+}
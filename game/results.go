@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package main
+
+import (
+	"game/client"
+	"game/model"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"image/color"
+	"strconv"
+	"time"
+)
+
+// resultsMaxRows is the most players the results list.
+const resultsMaxRows = 10
+
+// resultsColumns are the x of each column of the results table.
+var resultsColumns = []int{96, 176, 560, 704, 864, 1024}
+
+var resultsHighlight = color.RGBA{R: 255, G: 215, B: 90, A: 255}
+
+// resultsScene shows the standings the server sent at the end of a match,
+// until the next match starts.
+type resultsScene struct {
+	game  *Game
+	end   *client.MatchEnd
+	match *model.Match
+	next  time.Time
+}
+
+func (s *resultsScene) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		s.game.leave()
+		s.game.setScene(newMenuScene(s.game))
+		return nil
+	}
+	if s.game.match != s.match {
+		s.game.setScene(newMatchScene(s.game))
+	}
+	return nil
+}
+
+func (s *resultsScene) Draw(screen *ebiten.Image) {
+	drawTitle(screen, "Results")
+
+	header := []string{"#", "Player", "Score", "Diamonds", "Distance", "First"}
+	drawResultsRow(screen, header, 224, sceneHintColor)
+
+	for i, result := range s.end.Results {
+		if i == resultsMaxRows {
+			more := "and " + strconv.Itoa(len(s.end.Results)-i) + " more"
+			text.Draw(screen, more, mplusSmallFont, resultsColumns[1], 272+i*36, sceneHintColor)
+			break
+		}
+		name := result.Name
+
+		if result.Bot {
+			name += " (bot)"
+		}
+		first := "-"
+
+		if result.Diamonds > 0 {
+			first = strconv.FormatFloat(result.FirstPickup.Seconds(), 'f', 1, 64) + "s"
+		}
+		row := []string{
+			strconv.Itoa(result.Rank),
+			name,
+			strconv.Itoa(result.Score),
+			strconv.Itoa(result.Diamonds),
+			strconv.Itoa(result.Distance) + "px",
+			first,
+		}
+		clr := color.Color(color.White)
+
+		if result.Id == user.Id {
+			clr = resultsHighlight
+		}
+		drawResultsRow(screen, row, 272+i*36, clr)
+	}
+	seconds := int(time.Until(s.next).Seconds()) + 1
+
+	if seconds < 0 {
+		seconds = 0
+	}
+	drawHint(screen, "The next match starts in "+strconv.Itoa(seconds)+"s, Esc to leave")
+}
+
+func drawResultsRow(screen *ebiten.Image, cells []string, y int, clr color.Color) {
+	for i, cell := range cells {
+		text.Draw(screen, cell, mplusSmallFont, resultsColumns[i], y, clr)
+	}
+}
+
+func newResultsScene(game *Game, end *client.MatchEnd) *resultsScene {
+	return &resultsScene{
+		game:  game,
+		end:   end,
+		match: game.match,
+		next:  time.Now().Add(end.Intermission),
+	}
+}
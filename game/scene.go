@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package main
+
+import (
+	"errors"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"image/color"
+)
+
+// errQuit ends the game when the player quits from the menu.
+var errQuit = errors.New("quit")
+
+var (
+	sceneBackground = color.RGBA{R: 5, G: 2, B: 2, A: 255}
+	sceneHintColor  = color.Gray{Y: 160}
+	sceneErrorColor = color.RGBA{R: 230, G: 80, B: 80, A: 255}
+)
+
+// Scene is a screen of the game, the game updates and draws the current one
+// on each frame.
+type Scene interface {
+	Update() error
+	Draw(screen *ebiten.Image)
+}
+
+const (
+	menuPlay     = 0
+	menuServers  = 1
+	menuSettings = 2
+	menuQuit     = 3
+)
+
+var menuItems = []string{
+	menuPlay:     "Play",
+	menuServers:  "Servers",
+	menuSettings: "Settings",
+	menuQuit:     "Quit",
+}
+
+// menuScene is the main menu the game opens to.
+type menuScene struct {
+	game     *Game
+	selected int
+}
+
+func (s *menuScene) Update() error {
+	s.selected = moveSelection(s.selected, len(menuItems))
+
+	if !inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		return nil
+	}
+	switch s.selected {
+	case menuPlay:
+		s.game.play(s.game.config.Addr)
+	case menuServers:
+		s.game.setScene(newBrowserScene(s.game))
+	case menuSettings:
+		s.game.setScene(newSettingsScene(s.game))
+	case menuQuit:
+		return errQuit
+	}
+	return nil
+}
+
+func (s *menuScene) Draw(screen *ebiten.Image) {
+	drawTitle(screen, "Dungeon MST")
+	drawList(screen, menuItems, s.selected, 240)
+	drawHint(screen, "Up and Down to choose, Enter to confirm")
+}
+
+// moveSelection returns the item selected after the arrows pressed in this
+// frame, wrapping around the list.
+func moveSelection(selected int, count int) int {
+	if count == 0 {
+		return 0
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		return (selected + 1) % count
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		return (selected + count - 1) % count
+	}
+	return selected
+}
+
+// drawTitle clears the screen and draws the title of the scene.
+func drawTitle(screen *ebiten.Image, title string) {
+	screen.Fill(sceneBackground)
+	text.Draw(screen, title, mplusNormalFont, 96, 144, color.White)
+}
+
+// drawList draws the items from the given height, marking the selected one.
+func drawList(screen *ebiten.Image, items []string, selected int, y int) {
+	for i, item := range items {
+		prefix := "  "
+
+		if i == selected {
+			prefix = "> "
+		}
+		text.Draw(screen, prefix+item, mplusSmallFont, 96, y+i*48, color.White)
+	}
+}
+
+// drawHint draws the keys of the scene at the bottom of the screen.
+func drawHint(screen *ebiten.Image, hint string) {
+	text.Draw(screen, hint, mplusSmallFont, 96, screenHeight-64, sceneHintColor)
+}
+
+func newMenuScene(game *Game) *menuScene {
+	return &menuScene{game: game}
+}
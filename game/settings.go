@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package main
+
+import (
+	"game/config"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"unicode"
+)
+
+const (
+	settingsFieldName = 0
+	settingsFieldAddr = 1
+	settingsFieldTLS  = 2
+	settingsFields    = 3
+)
+
+// settingsScene lets the player set up the name and the server to connect
+// to, the game opens to it until there's a valid config.
+type settingsScene struct {
+	game   *Game
+	config config.Config
+	field  int
+	err    string
+}
+
+func (s *settingsScene) Update() error {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab):
+		s.field = (s.field + 1) % settingsFields
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		// The player can't leave until there's something to connect with
+		if s.game.config.Validate() == nil {
+			s.game.setScene(newMenuScene(s.game))
+		}
+		return nil
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if err := s.config.Validate(); err != nil {
+			s.err = err.Error()
+			return nil
+		}
+		s.game.setConfig(s.config)
+		s.game.setScene(newMenuScene(s.game))
+		return nil
+	}
+	s.field = moveSelection(s.field, settingsFields)
+
+	switch s.field {
+	case settingsFieldName:
+		s.config.Name = editText(s.config.Name, config.MaxNameLength)
+	case settingsFieldAddr:
+		s.config.Addr = editText(s.config.Addr, -1)
+	case settingsFieldTLS:
+		if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+			s.config.TLS = !s.config.TLS
+		}
+	}
+	return nil
+}
+
+func (s *settingsScene) Draw(screen *ebiten.Image) {
+	drawTitle(screen, "Settings")
+
+	tls := "off"
+
+	if s.config.TLS {
+		tls = "on"
+	}
+	fields := []string{
+		settingsFieldName: "Name: " + s.config.Name,
+		settingsFieldAddr: "Server: " + s.config.Addr,
+		settingsFieldTLS:  "TLS: " + tls,
+	}
+
+	if s.field != settingsFieldTLS {
+		fields[s.field] += "_"
+	}
+	drawList(screen, fields, s.field, 240)
+	text.Draw(screen, "Key bindings are saved to "+s.game.configPath, mplusSmallFont, 96, 432, sceneHintColor)
+
+	if s.err != "" {
+		text.Draw(screen, s.err, mplusSmallFont, 96, 528, sceneErrorColor)
+	}
+	drawHint(screen, "Tab to move, Space to toggle TLS, Enter to save, Esc to go back")
+}
+
+// editText returns the given text with the characters typed in this frame,
+// up to the given length or unbounded if it's negative.
+func editText(value string, length int) string {
+	runes := []rune(value)
+
+	for _, r := range ebiten.InputChars() {
+		if unicode.IsPrint(r) && (length < 0 || len(runes) < length) {
+			runes = append(runes, r)
+		}
+	}
+	if len(runes) > 0 && isKeyRepeated(ebiten.KeyBackspace) {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
+
+// isKeyRepeated tells whether the key was just pressed, or is held long
+// enough to repeat.
+func isKeyRepeated(key ebiten.Key) bool {
+	const delay = 30
+	const interval = 3
+	d := inpututil.KeyPressDuration(key)
+	return d == 1 || (d >= delay && (d-delay)%interval == 0)
+}
+
+func newSettingsScene(game *Game) *settingsScene {
+	return &settingsScene{
+		game:   game,
+		config: game.config,
+	}
+}
@@ -0,0 +1,368 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"fmt"
+	"math/rand"
+	"server/model"
+	"time"
+)
+
+const (
+	EasyBot   = "easy"
+	NormalBot = "normal"
+	HardBot   = "hard"
+)
+
+// BotTPS is the number of moves a bot makes per second, the same as the
+// frames of the game.
+const BotTPS = 60
+
+// botStuckTicks is how long a bot keeps pushing against something before it
+// gives up on its route.
+const botStuckTicks = BotTPS
+
+// botMaxDetours is how many times a bot sidesteps hazards on the way to each
+// point of its route, before walking over them.
+const botMaxDetours = 2
+
+// BotDifficulties are the configs of each bot difficulty.
+var BotDifficulties = map[string]BotConfig{
+	EasyBot:   {Reaction: time.Second, Optimality: 0.3},
+	NormalBot: {Reaction: 400 * time.Millisecond, Optimality: 0.7},
+	HardBot:   {Reaction: 100 * time.Millisecond, Optimality: 1},
+}
+
+// BotConfig defines how well a bot plays.
+//
+// Reaction is how long the bot stands still before chasing another diamond
+// once it loses its target. Optimality is the odds of chasing the closest
+// diamond rather than a random one.
+type BotConfig struct {
+	Reaction   time.Duration
+	Optimality float64
+}
+
+// GetBotConfig returns the config of the given difficulty.
+func GetBotConfig(difficulty string) (BotConfig, error) {
+	config, ok := BotDifficulties[difficulty]
+
+	if !ok {
+		return BotConfig{}, fmt.Errorf("unknown bot difficulty %q", difficulty)
+	}
+	return config, nil
+}
+
+// Bot plays a match like a runner controlled by a player, walking the routes
+// of the match nav towards the diamonds.
+type Bot struct {
+	rng     *rand.Rand
+	config  BotConfig
+	runner  model.Runner
+	graph   *matchGraph
+	spawn   int
+	target  *model.Diamond
+	skipped *model.Diamond
+	route   []model.Point
+	wait    int
+	stuck   int
+
+	// Steps left sidestepping a hazard, their direction and the number of
+	// sidesteps on the way to the next point of the route
+	detour          int
+	detourDirection int
+	detours         int
+}
+
+// SetMatch places the bot at the given spawn dungeon of a new match.
+func (b *Bot) SetMatch(match *model.Match, spawn int) {
+	if spawn < 0 || spawn >= len(match.Dungeons) {
+		spawn = 0
+	}
+	b.runner = model.NewRunner()
+	b.runner.SetDungeon(match.Dungeons[spawn])
+	b.graph = newMatchGraph(match)
+	b.spawn = spawn
+	b.target = nil
+	b.skipped = nil
+	b.route = nil
+	b.wait = 0
+	b.stuck = 0
+	b.detour = 0
+	b.detours = 0
+}
+
+// Position returns the position of the bot runner.
+func (b *Bot) Position() model.Point {
+	return model.NewPoint(b.runner.Rect.Left(), b.runner.Rect.Top())
+}
+
+// SetPosition moves the bot back to the position the server allowed.
+func (b *Bot) SetPosition(point model.Point) {
+	b.runner.SetPosition(point.X(), point.Y())
+}
+
+func (b *Bot) Stun(frames int) {
+	b.runner.Stun(frames)
+}
+
+// Next makes the move of the current tick. It returns the new position of the
+// bot and the index of the diamond it picked up, which is removed from the
+// match, or -1 if there's none.
+func (b *Bot) Next(match *model.Match) (model.Point, int) {
+	if b.graph == nil {
+		return b.Position(), -1
+	}
+	match.SetCurrentDungeonAndPaths(&b.runner)
+
+	if b.runner.IsOutSide() {
+		b.runner.SetDungeon(match.Dungeons[b.spawn])
+		b.route = nil
+	}
+	last := b.runner.Rect.Center()
+
+	b.steer(match)
+	b.runner.Update()
+
+	center := b.runner.Rect.Center()
+	moved := model.Distance(last, center)
+
+	switch {
+	case moved > model.SpeedBoost:
+		// A hazard sent the bot elsewhere, the target may lie behind it
+		b.skipped = b.target
+		b.loseTarget()
+	case moved == 0 && len(b.route) > 0 && b.wait == 0 && !b.runner.IsStunned():
+		b.stuck++
+
+		if b.stuck >= botStuckTicks {
+			b.loseTarget()
+			b.route = nil
+		}
+	default:
+		b.stuck = 0
+	}
+	for i, diamond := range match.Diamonds {
+		if b.runner.CheckDiamondCollision(diamond) {
+			match.Diamonds = append(match.Diamonds[:i], match.Diamonds[i+1:]...)
+			return b.Position(), i
+		}
+	}
+	return b.Position(), -1
+}
+
+// steer pushes the input towards the next point of the route, planning a new
+// route if the bot lost its target.
+func (b *Bot) steer(match *model.Match) {
+	if b.target != nil && !containsDiamond(match, b.target) {
+		b.loseTarget()
+	}
+	if b.wait > 0 {
+		b.wait--
+		return
+	}
+	if len(b.route) == 0 {
+		b.plan(match)
+	}
+	center := b.runner.Rect.Center()
+
+	for len(b.route) > 0 && b.route[0].Equals(&center) {
+		b.route = b.route[1:]
+		b.detours = 0
+	}
+	if len(b.route) == 0 {
+		return
+	}
+	directions := getDirectionsTowards(center, b.route[0])
+
+	if b.detour > 0 {
+		b.detour--
+
+		if b.canStep(b.detourDirection) {
+			b.runner.PushInput(b.detourDirection)
+			return
+		}
+	}
+	for _, direction := range directions {
+		if b.canStep(direction) {
+			b.runner.PushInput(direction)
+			return
+		}
+	}
+
+	// Sidestep the hazard ahead, or walk over it if there's no way around
+	for _, direction := range getPerpendicularDirections(directions[0]) {
+		if b.detours < botMaxDetours && b.canStep(direction) {
+			b.detours++
+			b.detour = model.HazardSizePx
+			b.detourDirection = direction
+			b.runner.PushInput(direction)
+			return
+		}
+	}
+	for _, direction := range directions {
+		if b.runner.CanMoveTowards(direction) {
+			b.runner.PushInput(direction)
+			return
+		}
+	}
+}
+
+// stepDeltas are the position deltas of a step indexed by movement
+// direction.
+var stepDeltas = [4][2]int{
+	model.MoveDirLeft:   {-1, 0},
+	model.MoveDirTop:    {0, -1},
+	model.MoveDirRight:  {1, 0},
+	model.MoveDirBottom: {0, 1},
+}
+
+// canStep tells whether the bot can walk one step towards the given
+// direction without stepping on spikes or a teleport pad. Other hazards are
+// harmless as long as the bot keeps walking.
+func (b *Bot) canStep(direction int) bool {
+	if !b.runner.CanMoveTowards(direction) {
+		return false
+	}
+	delta := stepDeltas[direction]
+	rect := b.runner.Rect
+	next := model.NewRect(
+		rect.Left()+delta[0],
+		rect.Top()+delta[1],
+		rect.Right()+delta[0],
+		rect.Bottom()+delta[1],
+	)
+	i := b.graph.dungeonAt(rect.Center())
+
+	if i == -1 {
+		return true
+	}
+	dungeon := b.graph.match.Dungeons[i]
+	hazard := dungeon.HazardAt(&next)
+
+	// Once on a hazard, walking off it is better than stepping on it again
+	if hazard == nil || hazard == dungeon.HazardAt(&rect) {
+		return true
+	}
+	return hazard.Kind() != model.HazardSpikes && hazard.Kind() != model.HazardTeleport
+}
+
+// loseTarget drops the target and waits for the reaction time before chasing
+// another one. Within a corridor, the bot keeps walking its route to the next
+// dungeon so it doesn't stop there.
+func (b *Bot) loseTarget() {
+	b.target = nil
+	b.stuck = 0
+	b.wait = int(b.config.Reaction.Seconds() * BotTPS)
+
+	if b.graph.dungeonAt(b.runner.Rect.Center()) != -1 {
+		b.route = nil
+		return
+	}
+	for i, point := range b.route {
+		if b.graph.dungeonAt(point) != -1 {
+			b.route = b.route[:i+1]
+			return
+		}
+	}
+	b.route = nil
+}
+
+// plan picks the diamond to chase, the closest one or a random one, and the
+// route to it. Cursed diamonds are never chased, and neither is the last one
+// skipped unless it's the only one left.
+func (b *Bot) plan(match *model.Match) {
+	if b.graph.dungeonAt(b.runner.Rect.Center()) == -1 {
+		return
+	}
+	center := b.runner.Rect.Center()
+	var candidates []*model.Diamond
+	var closest *model.Diamond
+	best := 0
+
+	for _, diamond := range match.Diamonds {
+		if diamond.Value().Points <= 0 || diamond == b.skipped {
+			continue
+		}
+		rect := diamond.Rect()
+		distance, ok := match.Nav().Distance(center, rect.Center())
+
+		if !ok {
+			continue
+		}
+		if closest == nil || distance < best {
+			closest = diamond
+			best = distance
+		}
+		candidates = append(candidates, diamond)
+	}
+	if closest == nil {
+		if b.skipped != nil {
+			b.skipped = nil
+			b.plan(match)
+		}
+		return
+	}
+	b.target = closest
+
+	if b.rng.Float64() >= b.config.Optimality {
+		b.target = candidates[b.rng.Intn(len(candidates))]
+	}
+	rect := b.target.Rect()
+	b.route, _ = match.Nav().Route(center, rect.Center())
+}
+
+// getDirectionsTowards returns the directions that take the given point
+// closer to the target, the axis it's farther along first.
+func getDirectionsTowards(point model.Point, target model.Point) []int {
+	dx := target.X() - point.X()
+	dy := target.Y() - point.Y()
+	horizontal := model.MoveDirRight
+	vertical := model.MoveDirBottom
+
+	if dx < 0 {
+		horizontal = model.MoveDirLeft
+	}
+	if dy < 0 {
+		vertical = model.MoveDirTop
+	}
+	switch {
+	case dy == 0:
+		return []int{horizontal}
+	case dx == 0:
+		return []int{vertical}
+	case abs(dx) >= abs(dy):
+		return []int{horizontal, vertical}
+	default:
+		return []int{vertical, horizontal}
+	}
+}
+
+func getPerpendicularDirections(direction int) []int {
+	if direction == model.MoveDirLeft || direction == model.MoveDirRight {
+		return []int{model.MoveDirTop, model.MoveDirBottom}
+	}
+	return []int{model.MoveDirLeft, model.MoveDirRight}
+}
+
+func containsDiamond(match *model.Match, diamond *model.Diamond) bool {
+	for _, d := range match.Diamonds {
+		if d == diamond {
+			return true
+		}
+	}
+	return false
+}
+
+// NewBot returns a bot playing with the given config, its choices are drawn
+// from the given seed.
+func NewBot(seed int64, config BotConfig) *Bot {
+	return &Bot{
+		rng:    rand.New(rand.NewSource(seed)),
+		config: config,
+		runner: model.NewRunner(),
+	}
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import "testing"
+
+func TestBotPicksUpDiamonds(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		match, err := NewRandomMatch(seed, DefaultMatchConfig)
+
+		if err != nil {
+			t.Fatal("FAILED to generate match:", err)
+		}
+		bot := NewBot(seed, BotDifficulties[HardBot])
+		bot.SetMatch(match, SpawnDungeons(match, 1)[0])
+
+		for tick := 0; tick < 120*BotTPS; tick++ {
+			bot.Next(match)
+		}
+		for _, diamond := range match.Diamonds {
+			if diamond.Value().Points > 0 {
+				t.Fatal("FAILED bot left diamonds behind from seed", seed)
+			}
+		}
+	}
+}
+
+func TestGetBotConfig(t *testing.T) {
+	if _, err := GetBotConfig(NormalBot); err != nil {
+		t.Fatal("FAILED to get normal bot config:", err)
+	}
+	if _, err := GetBotConfig("impossible"); err == nil {
+		t.Fatal("FAILED unknown difficulty must be an error")
+	}
+}
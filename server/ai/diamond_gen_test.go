@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDiamondPlacements(t *testing.T) {
+	placements := []string{
+		UniformPlacement,
+		SizePlacement,
+		LeavesPlacement,
+		FarPlacement,
+		TreasurePlacement,
+		CorridorPlacement,
+	}
+
+	for _, placement := range placements {
+		config := DefaultMatchConfig
+		config.Diamonds.Placement = placement
+		config.Diamonds.Count = 12
+		match, err := NewRandomMatch(1, config)
+
+		if err != nil {
+			t.Fatal("FAILED to place diamonds with", placement, err)
+		}
+		if len(match.Diamonds) != 12 {
+			t.Fatal("FAILED expected 12 diamonds with", placement, "got", len(match.Diamonds))
+		}
+		if report := Validate(match); !report.IsValid() {
+			t.Fatal("FAILED invalid diamonds with", placement, report.Err())
+		}
+	}
+}
+
+func TestCorridorDiamondsOutOfDungeons(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		config := DefaultMatchConfig
+		config.Diamonds.Placement = CorridorPlacement
+		config.Diamonds.Count = 12
+		match, err := NewRandomMatch(seed, config)
+
+		if err != nil {
+			t.Fatal("FAILED to place corridor diamonds:", err)
+		}
+		for _, diamond := range match.Diamonds {
+			rect := diamond.Rect()
+
+			for i, dungeon := range match.Dungeons {
+				if dungeon.Intersects(&rect) {
+					t.Fatal("FAILED corridor diamond within dungeon", i, "of seed", seed)
+				}
+			}
+		}
+	}
+}
+
+func TestDiamondFairness(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	dungeons, err := GenerateDungeons(rng, DefaultDungeonConfig)
+
+	if err != nil {
+		t.Fatal("FAILED to generate dungeons:", err)
+	}
+	paths := GetPaths(dungeons, DefaultPathConfig)
+	spawns := []int{0, len(dungeons) - 1}
+	config := DefaultDiamondConfig
+	config.Placement = FarPlacement
+	config.Count = 20
+	config.Tolerance = 0.2
+	placer := newDiamondPlacer(dungeons, paths, spawns, config)
+
+	for i := 0; i < config.Attempts && len(placer.diamonds) < config.Count; i++ {
+		placer.tryPlace(rng, config.Count)
+	}
+	if len(placer.diamonds) != config.Count {
+		t.Fatal("FAILED to place 20 fair diamonds, placed", len(placer.diamonds))
+	}
+	for s, owned := range placer.owned {
+		if owned > 12 {
+			t.Fatal("FAILED spawn", s, "is the closest to", owned, "of 20 diamonds")
+		}
+	}
+}
+
+func TestUniformDiamondsWithTolerance(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		dungeons, err := GenerateDungeons(rng, DefaultDungeonConfig)
+
+		if err != nil {
+			t.Fatal("FAILED to generate dungeons:", err)
+		}
+		paths := GetPaths(dungeons, DefaultPathConfig)
+		spawns := []int{0, len(dungeons) - 1}
+		config := DefaultDiamondConfig
+		config.Tolerance = 0.2
+		diamonds, err := GenerateDiamonds(rng, dungeons, paths, spawns, config)
+
+		if err != nil || len(diamonds) != len(dungeons) {
+			t.Fatal("FAILED to place one fair diamond per dungeon:", err)
+		}
+		placer := newDiamondPlacer(dungeons, paths, spawns, config)
+
+		for _, diamond := range diamonds {
+			rect := diamond.Rect()
+
+			if owner := placer.getOwner([]int{placer.graph.dungeonAt(rect.Center())}); owner != -1 {
+				placer.owned[owner]++
+			}
+		}
+		quota := int(math.Ceil(float64(len(diamonds)) / float64(len(spawns)) * (1 + config.Tolerance)))
+
+		for s, owned := range placer.owned {
+			if owned > quota {
+				t.Fatal("FAILED spawn", s, "of seed", seed, "is the closest to", owned, "of", len(diamonds), "diamonds")
+			}
+		}
+	}
+}
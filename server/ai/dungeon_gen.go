@@ -5,162 +5,275 @@
 package ai
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
 	"server/model"
-	"time"
 )
 
-const n = 100000
-const maxWidthFactor = 8
-const maxHeightFactor = 5
-
 const (
-	screenWidth  = 1280
-	screenHeight = 720
+	RejectionLayout  = "rejection"
+	BSPLayout        = "bsp"
+	PoissonLayout    = "poisson"
+	SeparationLayout = "separation"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
-func GenerateDungeons() []*model.Dungeon {
-	var dungeons []*model.Dungeon
-	dimension := model.NewDimension(screenWidth, screenHeight)
-	minDim := getMinSize()
-	maxDim := getMaxSize()
-	xMap := map[int]bool{}
-	yMap := map[int]bool{}
-
-	for i := 0; i < n; i++ {
-		p := getRandomPoint(dimension, maxDim)
-		factor := getRandomFactor()
-		w := factor.Width * minDim.Width()
-		h := factor.Height * minDim.Width()
-		l := p.X() - w/2
-		t := p.Y() - h/2
-		p0 := model.NewPoint(l, t)
-		rect := model.NewRect(l, t, l+w, t+h)
-		shouldContinue := false
-
-		for _, dungeon := range dungeons {
-			if dungeon.Intersects(&rect) {
-				shouldContinue = true
-				break
-			}
-		}
-		if shouldContinue {
-			continue
-		}
+// ErrUnsatisfiable is returned when the dungeons can't be generated as the
+// config requires.
+var ErrUnsatisfiable = errors.New("dungeon constraints are unsatisfiable")
 
-		// Check if there's a dungeon aligned to this one already
-		for i := 0; i <= model.PathWidthPx; i++ {
-			if xMap[rect.Left()+i] ||
-				xMap[rect.Cx()-model.PathWidthPx/2+i] ||
-				xMap[rect.Right()-i] ||
-				yMap[rect.Top()+i] ||
-				yMap[rect.Cy()-model.PathWidthPx/2+i] ||
-				yMap[rect.Bottom()-i] {
-				shouldContinue = true
-				break
-			}
-		}
-		if shouldContinue {
-			continue
-		}
+// DefaultDungeonConfig fills a 1280x720 world with as many dungeons as fit.
+var DefaultDungeonConfig = DungeonConfig{
+	Width:     1280,
+	Height:    720,
+	Rooms:     0,
+	MinFactor: model.DimensionFactor{Width: 1, Height: 1},
+	MaxFactor: model.DimensionFactor{Width: 8, Height: 5},
+	Margin:    0,
+	Attempts:  100000,
+	Layout:    RejectionLayout,
+}
 
-		// Update corners
-		xMap[rect.Left()] = true
-		xMap[rect.Cx()] = true
-		xMap[rect.Right()] = true
-		yMap[rect.Top()] = true
-		yMap[rect.Cy()] = true
-		yMap[rect.Bottom()] = true
-
-		// Fill wall widths to avoid paths colliding with walls
-		for i := 1; i <= model.PathWidthPx; i++ {
-			xMap[rect.Left()+i] = true
-			xMap[rect.Right()-i] = true
-			xMap[rect.Cx()-model.PathWidthPx/2+i] = true
-			yMap[rect.Top()+i] = true
-			yMap[rect.Bottom()-i] = true
-			yMap[rect.Cy()-model.PathWidthPx/2+i] = true
-		}
+// DungeonConfig defines the world the dungeons are generated in.
+//
+// Rooms is the number of dungeons to generate, if it's zero the generator
+// places as many dungeons as it can. MinFactor and MaxFactor bound the size of
+// each dungeon. Margin is the minimum space between dungeons, and between
+// them and the world edges. Attempts is the budget of placement tries, so the
+// generation always terminates. Layout is the name of the generator used.
+type DungeonConfig struct {
+	Width     int
+	Height    int
+	Rooms     int
+	MinFactor model.DimensionFactor
+	MaxFactor model.DimensionFactor
+	Margin    int
+	Attempts  int
+	Layout    string
+}
 
-		// Add the dungeon
-		dungeon := model.NewDungeon(p0, factor)
-		dungeons = append(dungeons, &dungeon)
+// Validate returns an error if no dungeon can be generated with this config.
+func (c *DungeonConfig) Validate() error {
+	unit := model.GetDungeonHorizontalUnitSize()
+	maxWidth := c.MaxFactor.Width*unit.Width() + 2*c.Margin
+	maxHeight := c.MaxFactor.Height*unit.Width() + 2*c.Margin
+
+	if c.Width <= 0 || c.Height <= 0 {
+		return fmt.Errorf("%w: world size must be positive", ErrUnsatisfiable)
+	}
+	if c.Rooms < 0 || c.Margin < 0 || c.Attempts <= 0 {
+		return fmt.Errorf("%w: rooms, margin and attempts can't be negative", ErrUnsatisfiable)
 	}
-	return dungeons
+	if c.MinFactor.Width < 1 || c.MinFactor.Height < 1 {
+		return fmt.Errorf("%w: min factor must be at least 1", ErrUnsatisfiable)
+	}
+	if c.MinFactor.Width > c.MaxFactor.Width || c.MinFactor.Height > c.MaxFactor.Height {
+		return fmt.Errorf("%w: min factor is greater than max factor", ErrUnsatisfiable)
+	}
+	if maxWidth > c.Width || maxHeight > c.Height {
+		return fmt.Errorf("%w: max dungeon doesn't fit in the world", ErrUnsatisfiable)
+	}
+	_, err := NewGenerator(c.Layout)
+	return err
 }
 
-func GetPaths(dungeons []*model.Dungeon) []*model.Path {
-	var paths []*model.Path
-	var tree []*model.Dungeon
-	done := map[*model.Dungeon]bool{}
+// GenerateDungeons lays out the dungeons with the generator of the config
+// layout.
+func GenerateDungeons(rng *rand.Rand, config DungeonConfig) ([]*model.Dungeon, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	generator, err := NewGenerator(config.Layout)
 
-	tree = append(tree, dungeons[0])
-	done[dungeons[0]] = true
+	if err != nil {
+		return nil, err
+	}
+	dungeons, err := generator.Generate(rng, config)
+
+	if err != nil {
+		return nil, err
+	}
+	if len(dungeons) < config.Rooms {
+		return nil, fmt.Errorf(
+			"%w: placed %d of %d rooms",
+			ErrUnsatisfiable,
+			len(dungeons),
+			config.Rooms,
+		)
+	}
+	if len(dungeons) == 0 {
+		return nil, fmt.Errorf("%w: no room was placed", ErrUnsatisfiable)
+	}
+	return dungeons, nil
+}
+
+// Generator lays out the dungeons of a match within the world defined by the
+// config.
+type Generator interface {
+	Generate(rng *rand.Rand, config DungeonConfig) ([]*model.Dungeon, error)
+}
 
-	for true {
-		if len(tree) == len(dungeons) {
-			break
+// NewGenerator returns the generator of the given layout, the empty layout
+// being the rejection one.
+func NewGenerator(layout string) (Generator, error) {
+	switch layout {
+	case "", RejectionLayout:
+		return RejectionGenerator{}, nil
+	case BSPLayout:
+		return BSPGenerator{}, nil
+	case PoissonLayout:
+		return PoissonGenerator{}, nil
+	case SeparationLayout:
+		return SeparationGenerator{}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown layout %q", ErrUnsatisfiable, layout)
+}
+
+// RejectionGenerator places dungeons of random size at random points,
+// rejecting the ones that overlap or are aligned to a close dungeon.
+type RejectionGenerator struct{}
+
+func (RejectionGenerator) Generate(rng *rand.Rand, config DungeonConfig) ([]*model.Dungeon, error) {
+	placement := newPlacement(config)
+	alignRadius := getAlignRadius(config)
+
+	for i := 0; i < config.Attempts && !placement.isDone(); i++ {
+		factor := getRandomFactor(rng, config)
+		p := getRandomPoint(rng, config, getDungeonSize(factor))
+		rect := newCenteredRect(p, factor)
+
+		// Dungeons close enough to get connected must not be aligned to avoid
+		// paths colliding with walls
+		if placement.fits(rect) && !placement.isAligned(rect, alignRadius) {
+			placement.add(rect, factor)
 		}
-		var a *model.Dungeon
-		var b *model.Dungeon
-		minDistance := 100000
+	}
+	return placement.dungeons, nil
+}
 
-		for _, d1 := range tree {
-			p1 := d1.Center()
+// placement keeps the dungeons placed so far by a generator.
+type placement struct {
+	config   DungeonConfig
+	dungeons []*model.Dungeon
+	rects    []model.Rect
+}
 
-			for _, d2 := range dungeons {
-				if done[d2] {
-					continue
-				}
+// isDone tells whether the target number of rooms has been placed.
+func (p *placement) isDone() bool {
+	return p.config.Rooms > 0 && len(p.dungeons) >= p.config.Rooms
+}
 
-				p2 := d2.Center()
-				distance := model.Distance(p1, p2)
+// fits tells whether a dungeon with the given rect lies within the world
+// margins and keeps the margin to the dungeons placed so far.
+func (p *placement) fits(rect model.Rect) bool {
+	margin := p.config.Margin
 
-				if distance < minDistance {
-					minDistance = distance
-					a = d1
-					b = d2
-				}
-			}
+	if rect.Left() < margin || rect.Top() < margin ||
+		rect.Right() > p.config.Width-margin || rect.Bottom() > p.config.Height-margin {
+		return false
+	}
+	spacing := model.NewRect(
+		rect.Left()-margin,
+		rect.Top()-margin,
+		rect.Right()+margin,
+		rect.Bottom()+margin,
+	)
+
+	for _, dungeon := range p.dungeons {
+		if dungeon.Intersects(&spacing) {
+			return false
 		}
+	}
+	return true
+}
 
-		if a != nil && b != nil {
-			tree = append(tree, b)
-			done[b] = true
+// isAligned tells whether the given rect is aligned to any dungeon placed
+// within the given radius.
+func (p *placement) isAligned(rect model.Rect, radius int) bool {
+	center := rect.Center()
 
-			path := a.GetPathFor(b)
-			//a.AddDoor(path) coming next
-			paths = append(paths, path)
+	for _, placed := range p.rects {
+		if model.Distance(center, placed.Center()) <= radius && isAligned(rect, placed) {
+			return true
 		}
 	}
-	return paths
+	return false
 }
 
-func getMinSize() model.Dimension {
-	size := model.GetDungeonHorizontalUnitSize()
-	baseSize := size.Width()
-	return model.NewDimension(baseSize, baseSize)
+func (p *placement) add(rect model.Rect, factor model.DimensionFactor) {
+	dungeon := model.NewDungeon(model.NewPoint(rect.Left(), rect.Top()), factor)
+	p.dungeons = append(p.dungeons, &dungeon)
+	p.rects = append(p.rects, rect)
+}
+
+func newPlacement(config DungeonConfig) *placement {
+	return &placement{config: config}
+}
+
+// isAligned tells whether a wall or the center of one rect is within a path
+// width of a wall or the center of the other one, on either axis.
+func isAligned(r1 model.Rect, r2 model.Rect) bool {
+	w := model.PathWidthPx
+	sw := w / 2
+	xs1 := [][2]int{{r1.Left(), r1.Left() + w}, {r1.Cx() - sw, r1.Cx() + sw}, {r1.Right() - w, r1.Right()}}
+	xs2 := [][2]int{{r2.Left(), r2.Left() + w}, {r2.Cx() - sw, r2.Cx() + sw}, {r2.Right() - w, r2.Right()}}
+	ys1 := [][2]int{{r1.Top(), r1.Top() + w}, {r1.Cy() - sw, r1.Cy() + sw}, {r1.Bottom() - w, r1.Bottom()}}
+	ys2 := [][2]int{{r2.Top(), r2.Top() + w}, {r2.Cy() - sw, r2.Cy() + sw}, {r2.Bottom() - w, r2.Bottom()}}
+	overlaps := func(a [][2]int, b [][2]int) bool {
+		for _, i := range a {
+			for _, j := range b {
+				if i[0] <= j[1] && j[0] <= i[1] {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return overlaps(xs1, xs2) || overlaps(ys1, ys2)
 }
 
-func getMaxSize() model.Dimension {
+// getAlignRadius returns the distance within which dungeons are likely to get
+// connected, so they must not be aligned.
+func getAlignRadius(config DungeonConfig) int {
+	unit := getMinSize()
+	return 2*unit.Width()*max(config.MaxFactor.Width, config.MaxFactor.Height) + 2*config.Margin
+}
+
+// newCenteredRect returns the rect of a dungeon with the given factor centered
+// at the given point.
+func newCenteredRect(center model.Point, factor model.DimensionFactor) model.Rect {
+	size := getDungeonSize(factor)
+	l := center.X() - size.SemiWidth()
+	t := center.Y() - size.SemiHeight()
+	return model.NewRect(l, t, l+size.Width(), t+size.Height())
+}
+
+func getDungeonSize(factor model.DimensionFactor) model.Dimension {
+	unit := getMinSize()
+	return model.NewDimension(factor.Width*unit.Width(), factor.Height*unit.Width())
+}
+
+func getMinSize() model.Dimension {
 	size := model.GetDungeonHorizontalUnitSize()
 	baseSize := size.Width()
-	return model.NewDimension(maxWidthFactor*baseSize, maxHeightFactor*baseSize)
+	return model.NewDimension(baseSize, baseSize)
 }
 
-func getRandomPoint(dimension model.Dimension, maxDim model.Dimension) model.Point {
-	cx := maxDim.SemiWidth() + int(float64(dimension.Width()-maxDim.Width())*rand.Float64())
-	cy := maxDim.SemiHeight() + int(float64(dimension.Height()-maxDim.Height())*rand.Float64())
+// getRandomPoint returns a random center for a dungeon of the given dimension
+// so that it lies within the world margins.
+func getRandomPoint(rng *rand.Rand, config DungeonConfig, dim model.Dimension) model.Point {
+	freeWidth := config.Width - dim.Width() - 2*config.Margin
+	freeHeight := config.Height - dim.Height() - 2*config.Margin
+	cx := config.Margin + dim.SemiWidth() + int(float64(freeWidth)*rng.Float64())
+	cy := config.Margin + dim.SemiHeight() + int(float64(freeHeight)*rng.Float64())
 	return model.NewPoint(cx, cy)
 }
 
-func getRandomFactor() model.DimensionFactor {
-	wFactor := 1 + int(math.Floor(float64(maxWidthFactor)*rand.Float64()))
-	hFactor := 1 + int(math.Floor(float64(maxHeightFactor)*rand.Float64()))
+func getRandomFactor(rng *rand.Rand, config DungeonConfig) model.DimensionFactor {
+	wRange := config.MaxFactor.Width - config.MinFactor.Width + 1
+	hRange := config.MaxFactor.Height - config.MinFactor.Height + 1
+	wFactor := config.MinFactor.Width + int(math.Floor(float64(wRange)*rng.Float64()))
+	hFactor := config.MinFactor.Height + int(math.Floor(float64(hRange)*rng.Float64()))
 	return model.DimensionFactor{Width: wFactor, Height: hFactor}
 }
@@ -4,26 +4,69 @@
 
 package ai
 
-import "server/model"
+import (
+	"math/rand"
+	"server/model"
+)
 
-func NewRandomMatch() *model.Match {
-	dungeons := GenerateDungeons()
-	paths := GetPaths(dungeons)
-	diamonds := generateDiamonds(dungeons)
-	return &model.Match{
-		Dungeons: dungeons,
-		Paths:    paths,
-		Diamonds: diamonds,
+// DefaultMatchConfig is the config matches are generated with unless another
+// one is given.
+var DefaultMatchConfig = MatchConfig{
+	Dungeons: DefaultDungeonConfig,
+	Paths:    DefaultPathConfig,
+	Diamonds: DefaultDiamondConfig,
+	Spawns:   DefaultSpawnConfig,
+	Pickups:  DefaultPickupConfig,
+	Hazards:  DefaultHazardConfig,
+}
+
+// MatchConfig defines how each part of a match is generated.
+type MatchConfig struct {
+	Dungeons DungeonConfig
+	Paths    PathConfig
+	Diamonds DiamondConfig
+	Spawns   SpawnConfig
+	Pickups  PickupConfig
+	Hazards  HazardConfig
+}
+
+// Validate returns an error if no match can be generated with this config.
+func (c *MatchConfig) Validate() error {
+	if err := c.Dungeons.Validate(); err != nil {
+		return err
+	}
+	if err := c.Diamonds.Validate(); err != nil {
+		return err
 	}
+	if err := c.Spawns.Validate(); err != nil {
+		return err
+	}
+	if err := c.Pickups.Validate(); err != nil {
+		return err
+	}
+	return c.Hazards.Validate()
 }
 
-func generateDiamonds(dungeons []*model.Dungeon) []*model.Diamond {
-	var diamonds []*model.Diamond
+// NewRandomMatch generates a match from the given seed. The same seed and
+// config always produce the same match, so it can be reproduced for bug
+// reports, replays or shared maps.
+func NewRandomMatch(seed int64, config MatchConfig) (*model.Match, error) {
+	rng := rand.New(rand.NewSource(seed))
+	dungeons, err := GenerateDungeons(rng, config.Dungeons)
+
+	if err != nil {
+		return nil, err
+	}
+	paths := GetPaths(dungeons, config.Paths)
+	diamonds, err := GenerateDiamonds(rng, dungeons, paths, LayoutSpawns(dungeons, paths), config.Diamonds)
 
-	for _, dungeon := range dungeons {
-		point := dungeon.RandomPoint(model.DiamondWidthPx)
-		diamond := model.NewDiamond(point)
-		diamonds = append(diamonds, &diamond)
+	if err != nil {
+		return nil, err
 	}
-	return diamonds
+	PlaceHazards(rng, dungeons, diamonds, config.Hazards)
+	return &model.Match{
+		Dungeons: dungeons,
+		Paths:    paths,
+		Diamonds: diamonds,
+	}, nil
 }
@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"server/model"
+	"testing"
+)
+
+func TestNewRandomMatchIsReproducible(t *testing.T) {
+	newMatchJSON := func(seed int64) *model.MatchJSON {
+		match, err := NewRandomMatch(seed, DefaultMatchConfig)
+
+		if err != nil {
+			t.Fatal("FAILED to generate match:", err)
+		}
+		return model.NewMatchJSON(match)
+	}
+	m1 := newMatchJSON(1)
+	m2 := newMatchJSON(1)
+	m3 := newMatchJSON(2)
+
+	if !reflect.DeepEqual(m1, m2) {
+		t.Fatal("FAILED same seed produced different matches")
+	}
+	if reflect.DeepEqual(m1, m3) {
+		t.Fatal("FAILED different seeds produced the same match")
+	}
+}
+
+func TestGenerateDungeonsConfig(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	config := DefaultDungeonConfig
+	config.Width = 4000
+	config.Height = 3000
+	config.Rooms = 40
+	config.Margin = 20
+	dungeons, err := GenerateDungeons(rng, config)
+
+	if err != nil {
+		t.Fatal("FAILED to generate dungeons:", err)
+	}
+	if len(dungeons) != 40 {
+		t.Fatal("FAILED expected 40 rooms, got", len(dungeons))
+	}
+
+	config.Width = 400
+
+	if _, err := GenerateDungeons(rng, config); !errors.Is(err, ErrUnsatisfiable) {
+		t.Fatal("FAILED max dungeon can't fit in the world")
+	}
+
+	config = DefaultDungeonConfig
+	config.Rooms = 1000
+
+	if _, err := GenerateDungeons(rng, config); !errors.Is(err, ErrUnsatisfiable) {
+		t.Fatal("FAILED 1000 rooms can't fit in the world")
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	layouts := []string{RejectionLayout, BSPLayout, PoissonLayout, SeparationLayout}
+
+	for _, layout := range layouts {
+		config := DefaultDungeonConfig
+		config.Width = 3000
+		config.Height = 2000
+		config.Margin = 10
+		config.Layout = layout
+		dungeons, err := GenerateDungeons(rand.New(rand.NewSource(1)), config)
+
+		if err != nil {
+			t.Fatal("FAILED to generate dungeons with layout", layout, err)
+		}
+		for i, d1 := range dungeons {
+			for _, d2 := range dungeons[i+1:] {
+				rect := model.NewRect(
+					d2.Cx()-d2.Width()/2,
+					d2.Cy()-d2.Height()/2,
+					d2.Cx()+d2.Width()/2,
+					d2.Cy()+d2.Height()/2,
+				)
+
+				if d1.Intersects(&rect) {
+					t.Fatal("FAILED dungeons overlap with layout", layout)
+				}
+			}
+		}
+		if _, err := NewRandomMatch(1, MatchConfig{config, DefaultPathConfig, DefaultDiamondConfig, DefaultSpawnConfig, DefaultPickupConfig, DefaultHazardConfig}); err != nil {
+			t.Fatal("FAILED to generate match with layout", layout, err)
+		}
+	}
+}
+
+func TestPlaceHazards(t *testing.T) {
+	match, err := NewRandomMatch(1, DefaultMatchConfig)
+
+	if err != nil {
+		t.Fatal("FAILED to generate match:", err)
+	}
+	hazards := 0
+
+	for _, dungeon := range match.Dungeons {
+		runner := model.NewRunner()
+		runner.SetDungeon(dungeon)
+
+		for _, hazard := range dungeon.Hazards() {
+			rect := hazard.Rect()
+			hazards++
+
+			if !dungeon.InBounds(&rect) {
+				t.Fatal("FAILED hazard must lie within its dungeon")
+			}
+			if runner.Rect.Intersects(&rect) {
+				t.Fatal("FAILED hazard must keep off the runner spawn")
+			}
+		}
+	}
+	if hazards == 0 {
+		t.Fatal("FAILED expected some hazards")
+	}
+	if !Validate(match).IsValid() {
+		t.Fatal("FAILED hazards must not break the match")
+	}
+}
@@ -0,0 +1,311 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"math"
+	"server/model"
+	"sort"
+)
+
+const (
+	EuclideanWeight Weight = 0
+	ManhattanWeight Weight = 1
+	CorridorWeight  Weight = 2
+)
+
+// DefaultPathConfig connects each dungeon to its closest neighbours by
+// Euclidean distance, with a few loops.
+var DefaultPathConfig = PathConfig{
+	Weight:     EuclideanWeight,
+	Neighbours: 8,
+	Loops:      0.1,
+}
+
+// Weight is the measure used to tell how expensive it is to connect two
+// dungeons with a path.
+type Weight int
+
+// Of returns the weight of the path going from dungeon a to dungeon b.
+func (w Weight) Of(a *model.Dungeon, b *model.Dungeon) int {
+	dx := abs(a.Cx() - b.Cx())
+	dy := abs(a.Cy() - b.Cy())
+
+	switch w {
+	case ManhattanWeight:
+		return dx + dy
+	case CorridorWeight:
+		// The length of the L-shaped corridor that lies outside both
+		// dungeons, see Dungeon.GetPathFor
+		return dy - min(dy, a.Height()/2) + dx - min(dx, b.Width()/2)
+	default:
+		return model.Distance(a.Center(), b.Center())
+	}
+}
+
+// PathConfig defines how the dungeons get connected.
+//
+// Neighbours is the number of closest dungeons each dungeon is a candidate to
+// be connected to, if it's zero or negative every pair of dungeons is a
+// candidate.
+//
+// Loops is the fraction in [0, 1] of the candidate edges left out of the
+// minimum spanning tree that are added back as extra paths, so there's more
+// than one route between dungeons.
+type PathConfig struct {
+	Weight     Weight
+	Neighbours int
+	Loops      float64
+}
+
+// Edge connects the dungeons at indices A and B of a dungeon slice.
+type Edge struct {
+	A      int
+	B      int
+	Weight int
+}
+
+// GetPaths connects the dungeons with the minimum spanning tree of their
+// candidate graph plus the loops defined by the config. Paths are routed
+// around the dungeons they don't connect, and open a door on the walls of the
+// dungeons they do.
+func GetPaths(dungeons []*model.Dungeon, config PathConfig) []*model.Path {
+	var paths []*model.Path
+	router := NewRouter(dungeons)
+
+	for _, edge := range getEdges(dungeons, router, config) {
+		path := router.Route(edge.A, edge.B)
+		dungeons[edge.A].AddDoor(path)
+		dungeons[edge.B].AddDoor(path)
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// CandidateEdges returns the edges from each dungeon to its k closest
+// dungeons, or every possible edge if k is not positive.
+func CandidateEdges(dungeons []*model.Dungeon, k int, weight Weight) []Edge {
+	n := len(dungeons)
+	added := map[Edge]bool{}
+	var edges []Edge
+
+	if k <= 0 || k >= n-1 {
+		k = n - 1
+	}
+
+	for i := range dungeons {
+		neighbours := make([]Edge, 0, k+1)
+
+		for j := range dungeons {
+			if i == j {
+				continue
+			}
+			a, b := min(i, j), max(i, j)
+			edge := Edge{
+				A:      a,
+				B:      b,
+				Weight: weight.Of(dungeons[a], dungeons[b]),
+			}
+			neighbours = insertNearest(neighbours, edge, k)
+		}
+
+		for _, edge := range neighbours {
+			if !added[edge] {
+				added[edge] = true
+				edges = append(edges, edge)
+			}
+		}
+	}
+	return edges
+}
+
+// MinimumSpanningTree returns the minimum spanning tree of the graph of n
+// nodes with the given edges by applying Kruskal's algorithm. If the graph is
+// not connected, the result is a minimum spanning forest instead.
+func MinimumSpanningTree(n int, edges []Edge) []Edge {
+	var tree []Edge
+	sorted := make([]Edge, len(edges))
+	sets := newUnionFind(n)
+
+	copy(sorted, edges)
+	sortEdges(sorted)
+
+	for _, edge := range sorted {
+		if len(tree) == n-1 {
+			break
+		}
+		if sets.union(edge.A, edge.B) {
+			tree = append(tree, edge)
+		}
+	}
+	return tree
+}
+
+func getEdges(dungeons []*model.Dungeon, router *Router, config PathConfig) []Edge {
+	candidates, tree := getTree(dungeons, config)
+	loops := getLoops(router, candidates, tree, config.Loops)
+	return append(tree, loops...)
+}
+
+func getTree(dungeons []*model.Dungeon, config PathConfig) ([]Edge, []Edge) {
+	n := len(dungeons)
+
+	if n < 2 {
+		return nil, nil
+	}
+	edges := CandidateEdges(dungeons, config.Neighbours, config.Weight)
+	tree := MinimumSpanningTree(n, edges)
+
+	// The nearest neighbours graph might be disconnected, then the complete
+	// graph is the fallback
+	if len(tree) < n-1 {
+		edges = CandidateEdges(dungeons, 0, config.Weight)
+		tree = MinimumSpanningTree(n, edges)
+	}
+	return edges, tree
+}
+
+// getLoops returns the lightest candidate edges that are not in the tree, up
+// to the given fraction of them. Edges the router can only connect with a path
+// crossing a dungeon other than the ones it connects are skipped.
+func getLoops(router *Router, candidates []Edge, tree []Edge, ratio float64) []Edge {
+	var loops []Edge
+	var rest []Edge
+	inTree := map[Edge]bool{}
+
+	for _, edge := range tree {
+		inTree[edge] = true
+	}
+	for _, edge := range candidates {
+		if !inTree[edge] {
+			rest = append(rest, edge)
+		}
+	}
+	count := int(math.Round(ratio * float64(len(rest))))
+	sortEdges(rest)
+
+	for _, edge := range rest {
+		if len(loops) >= count {
+			break
+		}
+		path := router.Route(edge.A, edge.B)
+
+		if !crossesDungeons(path, router.dungeons, edge) {
+			loops = append(loops, edge)
+		}
+	}
+	return loops
+}
+
+func crossesDungeons(path *model.Path, dungeons []*model.Dungeon, edge Edge) bool {
+	for i, dungeon := range dungeons {
+		if i == edge.A || i == edge.B {
+			continue
+		}
+		if dungeon.IntersectsPath(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// insertNearest inserts the edge into the sorted edges keeping only the k
+// lightest ones.
+func insertNearest(edges []Edge, edge Edge, k int) []Edge {
+	if len(edges) == k && !lessEdge(edge, edges[k-1]) {
+		return edges
+	}
+	i := len(edges)
+
+	if i < k {
+		edges = append(edges, edge)
+	} else {
+		i = k - 1
+	}
+	for ; i > 0 && lessEdge(edge, edges[i-1]); i-- {
+		edges[i] = edges[i-1]
+	}
+	edges[i] = edge
+	return edges
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		return lessEdge(edges[i], edges[j])
+	})
+}
+
+func lessEdge(e1 Edge, e2 Edge) bool {
+	if e1.Weight != e2.Weight {
+		return e1.Weight < e2.Weight
+	}
+	if e1.A != e2.A {
+		return e1.A < e2.A
+	}
+	return e1.B < e2.B
+}
+
+type unionFind struct {
+	parent []int
+	rank   []int
+}
+
+func (u *unionFind) find(x int) int {
+	for u.parent[x] != x {
+		u.parent[x] = u.parent[u.parent[x]]
+		x = u.parent[x]
+	}
+	return x
+}
+
+// union merges the sets of a and b, and tells whether they were disjoint.
+func (u *unionFind) union(a int, b int) bool {
+	ra := u.find(a)
+	rb := u.find(b)
+
+	if ra == rb {
+		return false
+	}
+	if u.rank[ra] < u.rank[rb] {
+		ra, rb = rb, ra
+	}
+	u.parent[rb] = ra
+
+	if u.rank[ra] == u.rank[rb] {
+		u.rank[ra]++
+	}
+	return true
+}
+
+func newUnionFind(n int) *unionFind {
+	parent := make([]int, n)
+	rank := make([]int, n)
+
+	for i := range parent {
+		parent[i] = i
+	}
+	return &unionFind{parent, rank}
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func min(a, b int) int {
+	if a <= b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a >= b {
+		return a
+	}
+	return b
+}
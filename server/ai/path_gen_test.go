@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"math/rand"
+	"server/model"
+	"strconv"
+	"testing"
+)
+
+func TestMinimumSpanningTree(t *testing.T) {
+	edges := []Edge{
+		{0, 1, 4},
+		{0, 2, 1},
+		{1, 2, 2},
+		{1, 3, 5},
+		{2, 3, 8},
+		{3, 4, 3},
+		{2, 4, 9},
+	}
+	tree := MinimumSpanningTree(5, edges)
+	total := 0
+
+	for _, edge := range tree {
+		total += edge.Weight
+	}
+	if len(tree) != 4 {
+		t.Fatal("FAILED tree must have 4 edges")
+	}
+	if total != 11 {
+		t.Fatal("FAILED tree weight must be 11, got", total)
+	}
+}
+
+func TestGetPathsConnectsEveryDungeon(t *testing.T) {
+	dungeons := newDungeonGrid(rand.New(rand.NewSource(1)), 100)
+
+	for _, weight := range []Weight{EuclideanWeight, ManhattanWeight, CorridorWeight} {
+		config := PathConfig{Weight: weight, Neighbours: 4}
+
+		if _, tree := getTree(dungeons, config); len(tree) != len(dungeons)-1 {
+			t.Fatal("FAILED tree is not spanning for weight", weight)
+		}
+	}
+}
+
+func TestGetPathsAddsLoops(t *testing.T) {
+	dungeons := newDungeonGrid(rand.New(rand.NewSource(1)), 100)
+	router := NewRouter(dungeons)
+	tree := getEdges(dungeons, router, PathConfig{Neighbours: 4})
+	loops := getEdges(dungeons, router, PathConfig{Neighbours: 4, Loops: 0.5})
+
+	if len(tree) != len(dungeons)-1 {
+		t.Fatal("FAILED edges without loops must be a tree")
+	}
+	if len(loops) <= len(tree) {
+		t.Fatal("FAILED no loops were added")
+	}
+	for _, edge := range loops[len(tree):] {
+		if crossesDungeons(router.Route(edge.A, edge.B), dungeons, edge) {
+			t.Fatal("FAILED loop", edge, "is routed across another dungeon")
+		}
+	}
+}
+
+func BenchmarkGetPaths(b *testing.B) {
+	for _, n := range []int{50, 200, 800} {
+		dungeons := newDungeonGrid(rand.New(rand.NewSource(1)), n)
+
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				GetPaths(dungeons, DefaultPathConfig)
+			}
+		})
+	}
+}
+
+// newDungeonGrid returns n dungeons placed on the cells of a square grid with
+// random sizes and offsets, so they never overlap. No two dungeon centers are
+// aligned, as the generator guarantees.
+func newDungeonGrid(rng *rand.Rand, n int) []*model.Dungeon {
+	var dungeons []*model.Dungeon
+	xMap := map[int]bool{}
+	yMap := map[int]bool{}
+	cols := 1
+
+	for cols*cols < n {
+		cols++
+	}
+	for i := 0; i < n; i++ {
+		factor := model.DimensionFactor{Width: 1 + rng.Intn(2), Height: 1 + rng.Intn(2)}
+		x := (i%cols)*320 + rng.Intn(32)
+		y := (i/cols)*320 + rng.Intn(32)
+
+		for xMap[x+factor.Width*32] {
+			x++
+		}
+		for yMap[y+factor.Height*32] {
+			y++
+		}
+		xMap[x+factor.Width*32] = true
+		yMap[y+factor.Height*32] = true
+		dungeon := model.NewDungeon(model.NewPoint(x, y), factor)
+		dungeons = append(dungeons, &dungeon)
+	}
+	return dungeons
+}
@@ -0,0 +1,30 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import "testing"
+
+func TestSpawnDungeons(t *testing.T) {
+	match, err := NewRandomMatch(1, DefaultMatchConfig)
+
+	if err != nil {
+		t.Fatal("FAILED to generate match:", err)
+	}
+	spawns := SpawnDungeons(match, 4)
+	picked := map[int]bool{}
+
+	if len(spawns) != 4 {
+		t.Fatal("FAILED expected 4 spawns, got", len(spawns))
+	}
+	for _, spawn := range spawns {
+		if picked[spawn] {
+			t.Fatal("FAILED spawns must be different dungeons", spawns)
+		}
+		picked[spawn] = true
+	}
+	if len(SpawnDungeons(match, len(match.Dungeons)+1)) > len(match.Dungeons) {
+		t.Fatal("FAILED there can't be more spawns than dungeons")
+	}
+}
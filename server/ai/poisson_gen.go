@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"math"
+	"math/rand"
+	"server/model"
+)
+
+// poissonCandidates is the number of samples tried around each active sample
+// before it's retired, as in Bridson's algorithm.
+const poissonCandidates = 30
+
+// PoissonGenerator samples dungeon centers with Poisson-disc sampling so they
+// are evenly spread, and places at each center the largest random dungeon
+// that fits.
+type PoissonGenerator struct{}
+
+func (PoissonGenerator) Generate(rng *rand.Rand, config DungeonConfig) ([]*model.Dungeon, error) {
+	placement := newPlacement(config)
+	radius := getPoissonRadius(config)
+	cell := radius / math.Sqrt2
+	cols := int(float64(config.Width)/cell) + 1
+	rows := int(float64(config.Height)/cell) + 1
+	grid := make([]int, cols*rows)
+	var samples [][2]float64
+	var active []int
+	attempts := 0
+
+	for i := range grid {
+		grid[i] = -1
+	}
+	cellOf := func(x float64, y float64) (int, int) {
+		return int(x / cell), int(y / cell)
+	}
+	isFar := func(x float64, y float64) bool {
+		cx, cy := cellOf(x, y)
+
+		for j := max(0, cy-2); j <= min(rows-1, cy+2); j++ {
+			for i := max(0, cx-2); i <= min(cols-1, cx+2); i++ {
+				s := grid[j*cols+i]
+
+				if s != -1 && math.Hypot(samples[s][0]-x, samples[s][1]-y) < radius {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	addSample := func(x float64, y float64) {
+		cx, cy := cellOf(x, y)
+		grid[cy*cols+cx] = len(samples)
+		active = append(active, len(samples))
+		samples = append(samples, [2]float64{x, y})
+		placeDungeonAt(rng, placement, model.NewPoint(int(x), int(y)))
+	}
+
+	addSample(rng.Float64()*float64(config.Width), rng.Float64()*float64(config.Height))
+
+	for len(active) > 0 && attempts < config.Attempts && !placement.isDone() {
+		k := rng.Intn(len(active))
+		s := samples[active[k]]
+		found := false
+
+		for i := 0; i < poissonCandidates && attempts < config.Attempts; i++ {
+			attempts++
+			angle := 2 * math.Pi * rng.Float64()
+			distance := radius * (1 + rng.Float64())
+			x := s[0] + distance*math.Cos(angle)
+			y := s[1] + distance*math.Sin(angle)
+
+			if x < 0 || y < 0 || x >= float64(config.Width) || y >= float64(config.Height) {
+				continue
+			}
+			if isFar(x, y) {
+				addSample(x, y)
+				found = true
+				break
+			}
+		}
+		if !found {
+			active[k] = active[len(active)-1]
+			active = active[:len(active)-1]
+		}
+	}
+	return placement.dungeons, nil
+}
+
+// placeDungeonAt places a dungeon of random factor centered at the given
+// point, shrinking it until it fits. Nothing is placed if not even the
+// smallest dungeon fits.
+func placeDungeonAt(rng *rand.Rand, placement *placement, center model.Point) {
+	config := placement.config
+	factor := getRandomFactor(rng, config)
+
+	for factor.Width >= config.MinFactor.Width && factor.Height >= config.MinFactor.Height {
+		size := getDungeonSize(factor)
+		l := center.X() - size.SemiWidth()
+		t := center.Y() - size.SemiHeight()
+
+		if l >= 0 && t >= 0 {
+			rect := model.NewRect(l, t, l+size.Width(), t+size.Height())
+
+			if placement.fits(rect) {
+				placement.add(rect, factor)
+				return
+			}
+		}
+
+		// Shrink the longest side first
+		if factor.Width*config.MaxFactor.Height >= factor.Height*config.MaxFactor.Width {
+			factor.Width--
+		} else {
+			factor.Height--
+		}
+	}
+}
+
+// getPoissonRadius returns the minimum distance between samples, that is an
+// average dungeon plus room for a path around it.
+func getPoissonRadius(config DungeonConfig) float64 {
+	unit := getMinSize()
+	factors := config.MinFactor.Width + config.MaxFactor.Width +
+		config.MinFactor.Height + config.MaxFactor.Height
+	return float64(unit.Width()*factors)/4 + float64(config.Margin+model.PathWidthPx)
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"server/model"
+	"testing"
+)
+
+func TestRouterAvoidsDungeons(t *testing.T) {
+	newDungeon := func(x int, y int, w int, h int) *model.Dungeon {
+		dungeon := model.NewDungeon(model.NewPoint(x, y), model.DimensionFactor{Width: w, Height: h})
+		return &dungeon
+	}
+	dungeons := []*model.Dungeon{
+		newDungeon(100, 100, 1, 1),
+		newDungeon(600, 400, 1, 1),
+		newDungeon(90, 380, 2, 2), // Blocks the elbow from the first one
+		newDungeon(590, 90, 2, 2), // Blocks the elbow from the second one
+	}
+	path := NewRouter(dungeons).Route(0, 1)
+
+	if len(path.Lines()) < 3 {
+		t.Fatal("FAILED expected a corridor of more than two segments")
+	}
+	if dungeons[2].IntersectsPath(path) || dungeons[3].IntersectsPath(path) {
+		t.Fatal("FAILED corridor crosses a dungeon")
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"math"
+	"math/rand"
+	"server/model"
+)
+
+// maxSeparationSteps bounds the number of steering steps, the attempts of the
+// config bound them too.
+const maxSeparationSteps = 1000
+
+// SeparationGenerator spawns the dungeons overlapping around the center of
+// the world and steers them apart until they're separated. The dungeons that
+// are still overlapping or out of the world at the end are dropped.
+type SeparationGenerator struct{}
+
+func (SeparationGenerator) Generate(rng *rand.Rand, config DungeonConfig) ([]*model.Dungeon, error) {
+	placement := newPlacement(config)
+	n := getSeparationCount(config)
+	factors := make([]model.DimensionFactor, n)
+	sizes := make([][2]float64, n)
+	centers := make([][2]float64, n)
+	steps := min(maxSeparationSteps, config.Attempts)
+	margin := float64(config.Margin)
+
+	for i := range centers {
+		angle := 2 * math.Pi * rng.Float64()
+		distance := rng.Float64()
+		factors[i] = getRandomFactor(rng, config)
+		size := getDungeonSize(factors[i])
+		sizes[i] = [2]float64{float64(size.Width()), float64(size.Height())}
+		centers[i] = [2]float64{
+			float64(config.Width)/2 + distance*math.Cos(angle)*float64(config.Width)/4,
+			float64(config.Height)/2 + distance*math.Sin(angle)*float64(config.Height)/4,
+		}
+	}
+
+	for step := 0; step < steps; step++ {
+		moved := false
+
+		for i := range centers {
+			for j := i + 1; j < n; j++ {
+				// Overlap of the dungeons, plus the margin, on each axis
+				dx := centers[j][0] - centers[i][0]
+				dy := centers[j][1] - centers[i][1]
+				ox := (sizes[i][0]+sizes[j][0])/2 + margin + 1 - math.Abs(dx)
+				oy := (sizes[i][1]+sizes[j][1])/2 + margin + 1 - math.Abs(dy)
+
+				if ox <= 0 || oy <= 0 {
+					continue
+				}
+				moved = true
+
+				// Push both apart along the axis of least overlap
+				if ox < oy {
+					push := math.Copysign(ox/2, dx)
+					centers[i][0] -= push
+					centers[j][0] += push
+				} else {
+					push := math.Copysign(oy/2, dy)
+					centers[i][1] -= push
+					centers[j][1] += push
+				}
+			}
+		}
+		if !moved {
+			break
+		}
+
+		// Keep them inside the world
+		for i := range centers {
+			for axis, bound := range []int{config.Width, config.Height} {
+				low := margin + sizes[i][axis]/2
+				high := float64(bound) - margin - sizes[i][axis]/2
+				centers[i][axis] = math.Max(low, math.Min(high, centers[i][axis]))
+			}
+		}
+	}
+
+	for i := range centers {
+		if placement.isDone() {
+			break
+		}
+		l := int(math.Round(centers[i][0] - sizes[i][0]/2))
+		t := int(math.Round(centers[i][1] - sizes[i][1]/2))
+
+		if l < 0 || t < 0 {
+			continue
+		}
+		rect := model.NewRect(l, t, l+int(sizes[i][0]), t+int(sizes[i][1]))
+
+		if placement.fits(rect) {
+			placement.add(rect, factors[i])
+		}
+	}
+	return placement.dungeons, nil
+}
+
+// getSeparationCount returns the number of dungeons to spawn, that is the
+// rooms required or the number of average dungeons that fill about a third
+// of the world.
+func getSeparationCount(config DungeonConfig) int {
+	if config.Rooms > 0 {
+		return config.Rooms
+	}
+	unit := getMinSize()
+	w := unit.Width()*(config.MinFactor.Width+config.MaxFactor.Width)/2 + config.Margin
+	h := unit.Width()*(config.MinFactor.Height+config.MaxFactor.Height)/2 + config.Margin
+	return max(1, config.Width*config.Height/(3*w*h))
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpawner(t *testing.T) {
+	start := time.Now()
+	config := DefaultMatchConfig
+	config.Spawns.Max = 4
+	match, err := NewRandomMatch(1, config)
+
+	if err != nil {
+		t.Fatal("FAILED to generate match:", err)
+	}
+	match.Diamonds = match.Diamonds[:1]
+	spawner := NewSpawner(1, config, match, start)
+
+	if diamonds, _ := spawner.Next(match, start.Add(time.Second)); len(diamonds) != 0 {
+		t.Fatal("FAILED no wave is due before the interval")
+	}
+	diamonds, err := spawner.Next(match, start.Add(config.Spawns.Interval))
+
+	if err != nil || len(diamonds) != config.Spawns.Max-1 {
+		t.Fatal("FAILED wave must fill up to the max diamonds, got", len(diamonds), err)
+	}
+
+	config.Spawns.Mode = CooldownRespawn
+	spawner = NewSpawner(1, config, match, start)
+	spawner.Taken(start)
+
+	if diamonds, _ := spawner.Next(match, start.Add(time.Second)); len(diamonds) != 0 {
+		t.Fatal("FAILED diamond must not respawn before its cooldown")
+	}
+	if diamonds, _ := spawner.Next(match, start.Add(config.Spawns.Interval)); len(diamonds) != 1 {
+		t.Fatal("FAILED taken diamond must respawn after its cooldown")
+	}
+}
+
+func TestPickupSpawner(t *testing.T) {
+	start := time.Now()
+	match, err := NewRandomMatch(1, DefaultMatchConfig)
+
+	if err != nil {
+		t.Fatal("FAILED to generate match:", err)
+	}
+	spawner := NewPickupSpawner(1, DefaultMatchConfig, match, start)
+	interval := DefaultPickupConfig.Interval
+
+	if spawner.Next(match, start.Add(time.Second)) != nil {
+		t.Fatal("FAILED no pickup is due before the interval")
+	}
+	for i := 1; i <= DefaultPickupConfig.Max+1; i++ {
+		if pickup := spawner.Next(match, start.Add(time.Duration(i)*interval)); pickup != nil {
+			match.Pickups = append(match.Pickups, pickup)
+		}
+	}
+	if len(match.Pickups) != DefaultPickupConfig.Max {
+		t.Fatal("FAILED pickups must spawn up to the max, got", len(match.Pickups))
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package ai
+
+import (
+	"server/model"
+	"testing"
+)
+
+func TestValidateGeneratedMatches(t *testing.T) {
+	layouts := []string{RejectionLayout, BSPLayout, PoissonLayout, SeparationLayout}
+
+	for _, layout := range layouts {
+		config := DefaultMatchConfig
+		config.Dungeons.Layout = layout
+
+		for seed := int64(1); seed <= 3; seed++ {
+			match, err := NewRandomMatch(seed, config)
+
+			if err != nil {
+				t.Fatal("FAILED to generate match with layout", layout, err)
+			}
+			report := Validate(match)
+
+			if !report.IsValid() {
+				t.Fatal("FAILED invalid match with layout", layout, "seed", seed, report.Err())
+			}
+			if report.Metrics.Rooms != len(match.Dungeons) || report.Metrics.AverageDistance <= 0 {
+				t.Fatal("FAILED wrong metrics", report.Metrics)
+			}
+		}
+	}
+}
+
+func TestValidateFindsProblems(t *testing.T) {
+	d1 := model.NewDungeon(model.NewPoint(0, 0), model.DimensionFactor{Width: 2, Height: 2})
+	d2 := model.NewDungeon(model.NewPoint(500, 500), model.DimensionFactor{Width: 2, Height: 2})
+	diamond := model.NewDiamond(model.NewPoint(d2.Cx(), d2.Cy()))
+	match := &model.Match{
+		Dungeons: []*model.Dungeon{&d1, &d2},
+		Diamonds: []*model.Diamond{&diamond},
+	}
+	report := Validate(match)
+
+	if report.IsValid() || len(report.Problems) != 2 {
+		t.Fatal("FAILED expected a disconnected dungeon and an unreachable diamond", report.Problems)
+	}
+}
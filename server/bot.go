@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/websocket"
+	"server/ai"
+	"server/model"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var errBotClosed = errors.New("bot connection closed")
+
+// botConn is the in-memory connection of a bot. It follows the match from
+// the messages the hub writes to it, and the hub reads the bot moves from it
+// once per tick, as if they came from the game.
+type botConn struct {
+	mu     sync.Mutex
+	id     int
+	bot    *ai.Bot
+	match  *model.Match
+	ticker *time.Ticker
+	quit   chan struct{}
+	once   sync.Once
+}
+
+func (c *botConn) ReadMessage() (int, []byte, error) {
+	for {
+		select {
+		case <-c.quit:
+			return 0, nil, errBotClosed
+		case <-c.ticker.C:
+		}
+		c.mu.Lock()
+
+		if c.match == nil {
+			c.mu.Unlock()
+			continue
+		}
+		point, diamondIndex := c.bot.Next(c.match)
+		c.mu.Unlock()
+
+		update := &Update{
+			PointJSON:    *model.NewPointJSON(&point),
+			DiamondIndex: diamondIndex,
+			PickupIndex:  -1,
+		}
+		enc, err := json.Marshal(update)
+		return websocket.TextMessage, enc, err
+	}
+}
+
+func (c *botConn) WriteJSON(v interface{}) error {
+	data, ok := v.(*ResponseData)
+
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch data.Type {
+	case DataTypeJoinAccepted:
+		accepted := &JoinAccepted{}
+
+		if err := json.Unmarshal([]byte(data.Body), accepted); err != nil {
+			return err
+		}
+		c.id = accepted.Id
+	case DataTypeGameInitialization:
+		init := &MatchInit{}
+
+		if err := json.Unmarshal([]byte(data.Body), init); err != nil {
+			return err
+		}
+		c.match = init.MatchJSON.ToMatch()
+		c.bot.SetMatch(c.match, init.Spawn)
+	case DataTypeUpdate:
+		if c.match == nil {
+			return nil
+		}
+		update := &Update{}
+
+		if err := json.Unmarshal([]byte(data.Body), update); err != nil {
+			return err
+		}
+		if update.StunnedId == c.id {
+			c.bot.Stun(int(update.Stun.Seconds() * ai.BotTPS))
+		}
+		if update.Id == c.id {
+			// The server rejected the move
+			if update.Blocked {
+				c.bot.SetPosition(*update.PointJSON.ToPoint())
+			}
+			return nil
+		}
+		if update.DiamondIndex >= 0 && update.DiamondIndex < len(c.match.Diamonds) {
+			c.match.Diamonds = append(c.match.Diamonds[:update.DiamondIndex], c.match.Diamonds[update.DiamondIndex+1:]...)
+		}
+	case DataTypeDiamondSpawn:
+		if c.match == nil {
+			return nil
+		}
+		spawn := &DiamondSpawn{}
+
+		if err := json.Unmarshal([]byte(data.Body), spawn); err != nil {
+			return err
+		}
+		for _, diamondJSON := range spawn.DiamondsJSON {
+			c.match.Diamonds = append(c.match.Diamonds, diamondJSON.ToDiamond())
+		}
+	}
+	return nil
+}
+
+func (c *botConn) Close() error {
+	c.once.Do(func() {
+		c.ticker.Stop()
+		close(c.quit)
+	})
+	return nil
+}
+
+func newBotConn(seed int64, config ai.BotConfig) *botConn {
+	return &botConn{
+		id:     -1,
+		bot:    ai.NewBot(seed, config),
+		ticker: time.NewTicker(time.Second / ai.BotTPS),
+		quit:   make(chan struct{}),
+	}
+}
+
+// addBots registers the given number of bots to the hub, they join like any
+// other player.
+func addBots(hub *Hub, count int, config ai.BotConfig) {
+	for i := 1; i <= count; i++ {
+		conn := newBotConn(time.Now().UnixNano()+int64(i), config)
+		client := NewClient(conn, nextId(), "Bot "+strconv.Itoa(i))
+		client.bot = true
+
+		client.SendId()
+		go client.Handle()
+
+		hub.Register(client)
+	}
+}
@@ -6,29 +6,53 @@ package main
 
 import (
 	"encoding/json"
-	"github.com/gorilla/websocket"
 	"log"
 	"server/model"
 	"time"
 )
 
+// Conn is the connection to a client, a websocket for players or an in-memory
+// one for bots.
+type Conn interface {
+	ReadMessage() (int, []byte, error)
+	WriteJSON(v interface{}) error
+	Close() error
+}
+
 type Client struct {
 	PointJSON model.PointJSON
 	Score     int
 	id        int
 	name      string
-	conn      *websocket.Conn
+	spawn     int
+	placed    bool
+	bot       bool
+	stunned   time.Time
+	effects   [model.PickupKinds]time.Time
+	stats     matchStats
+	conn      Conn
 	ch        chan *ResponseData
 	quit      chan struct{}
 }
 
-func (c *Client) InitGame(match *model.Match, time time.Duration, players []*PlayerJoin) {
-	matchJSON := model.NewMatchJSON(match)
-	matchInit := &MatchInit{
-		MatchJSON:     matchJSON,
-		RemainingTime: time,
-		Players:       players,
+// matchStats is what the hub measures of a client in the current match.
+type matchStats struct {
+	diamonds    int
+	distance    int
+	firstPickup time.Duration
+}
+
+// move adds the step between the given positions to the distance walked,
+// unless it's too long for a step like when a hazard sends the runner away.
+func (s *matchStats) move(from model.PointJSON, to model.PointJSON) {
+	step := model.Distance(*from.ToPoint(), *to.ToPoint())
+
+	if step <= model.SpeedBoost {
+		s.distance += step
 	}
+}
+
+func (c *Client) InitGame(matchInit *MatchInit) {
 	enc, err := json.Marshal(matchInit)
 
 	if err != nil {
@@ -69,7 +93,7 @@ func (c *Client) Handle() {
 		select {
 		case <-c.quit:
 			if err := c.conn.Close(); err != nil {
-				log.Printf("Failed to close %s client connection: %v\n", c.id, err)
+				log.Printf("Failed to close %d client connection: %v\n", c.id, err)
 			}
 			return
 		case data := <-c.ch:
@@ -85,12 +109,13 @@ func (c *Client) Close() {
 	close(c.quit)
 }
 
-func NewClient(conn *websocket.Conn, id int, name string) *Client {
+func NewClient(conn Conn, id int, name string) *Client {
 	return &Client{
-		id:   id,
-		name: name,
-		conn: conn,
-		ch:   make(chan *ResponseData),
-		quit: make(chan struct{}),
+		id:    id,
+		name:  name,
+		spawn: -1,
+		conn:  conn,
+		ch:    make(chan *ResponseData),
+		quit:  make(chan struct{}),
 	}
 }
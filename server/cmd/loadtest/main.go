@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"server/ai"
+	"server/headless"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Connects many headless players to a running server, lets them play for a
+// while and reports how the server kept up with them.
+
+var (
+	addr          = flag.String("addr", "localhost:8080", "http service address")
+	clients       = flag.Int("clients", 100, "number of players")
+	duration      = flag.Duration("duration", time.Minute, "time the players play for")
+	ramp          = flag.Duration("ramp", 10*time.Millisecond, "time between each player joining")
+	botDifficulty = flag.String("bot-difficulty", ai.NormalBot, "how well the players play: easy, normal or hard")
+)
+
+func main() {
+	flag.Parse()
+	log.SetFlags(0)
+
+	config, err := ai.GetBotConfig(*botDifficulty)
+
+	if err != nil {
+		log.Fatal("Invalid bot config: " + err.Error())
+	}
+	quit := make(chan struct{})
+	var wg sync.WaitGroup
+	var connected []*headless.Client
+	failed := 0
+
+	for i := 1; i <= *clients; i++ {
+		client, err := headless.Dial(*addr, "Load "+strconv.Itoa(i), time.Now().UnixNano(), config)
+
+		if err != nil {
+			log.Println("Dial error:", err)
+			failed++
+			continue
+		}
+		connected = append(connected, client)
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := client.Run(quit); err != nil {
+				log.Printf("Player %d error: %v\n", client.Id(), err)
+			}
+		}()
+		time.Sleep(*ramp)
+	}
+	log.Printf("%d players joined, %d failed\n", len(connected), failed)
+
+	time.Sleep(*duration)
+	close(quit)
+	wg.Wait()
+
+	report(connected, *duration)
+}
+
+func report(clients []*headless.Client, duration time.Duration) {
+	total := headless.Stats{}
+
+	for _, client := range clients {
+		total.Add(client.Stats())
+	}
+	seconds := duration.Seconds()
+
+	fmt.Printf("players:   %d\n", len(clients))
+	fmt.Printf("matches:   %d\n", total.Matches)
+	fmt.Printf("diamonds:  %d\n", total.Diamonds)
+	fmt.Printf("sent:      %d (%.0f/s)\n", total.Sent, float64(total.Sent)/seconds)
+	fmt.Printf("received:  %d (%.0f/s)\n", total.Received, float64(total.Received)/seconds)
+	fmt.Printf("latency:   mean %v, p50 %v, p95 %v, p99 %v, max %v\n",
+		total.MeanLatency(),
+		total.Percentile(0.5),
+		total.Percentile(0.95),
+		total.Percentile(0.99),
+		total.Percentile(1),
+	)
+}
@@ -0,0 +1,271 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+// Package headless plays the game without a window, moving a bot runner over
+// a real connection to the server, to load-test it with many fake players.
+package headless
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"server/ai"
+	"server/model"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// The message types sent by the server
+const (
+	dataTypeGameInitialization = 0
+	dataTypeUpdate             = 1
+	dataTypeJoinAccepted       = 3
+	dataTypeDiamondSpawn       = 6
+)
+
+var ErrNotAccepted = errors.New("server didn't accept the player")
+
+type responseData struct {
+	Type int
+	Body string
+}
+
+type joinAccepted struct {
+	Id int
+}
+
+type matchInit struct {
+	MatchJSON *model.MatchJSON
+	Spawn     int
+}
+
+type diamondSpawn struct {
+	DiamondsJSON []*model.DiamondJSON
+}
+
+type update struct {
+	Id           int
+	PointJSON    model.PointJSON
+	DiamondIndex int
+	PickupIndex  int
+	Blocked      bool
+	StunnedId    int
+	Stun         time.Duration
+}
+
+// Client is a fake player connected to the server. A bot moves its runner
+// once per frame and picks up diamonds like a player would.
+type Client struct {
+	mu    sync.Mutex
+	conn  *websocket.Conn
+	id    int
+	bot   *ai.Bot
+	match *model.Match
+	stats Stats
+
+	// When each update still on its way back was sent, they come back in the
+	// same order
+	pending []time.Time
+}
+
+func (c *Client) Id() int {
+	return c.id
+}
+
+// Stats returns a copy of the stats gathered so far.
+func (c *Client) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Latencies = append([]time.Duration(nil), c.stats.Latencies...)
+	return stats
+}
+
+// Run plays until quit is closed or the connection fails, sending an update
+// each frame once the first match arrives.
+func (c *Client) Run(quit <-chan struct{}) error {
+	done := make(chan error, 1)
+	ticker := time.NewTicker(time.Second / ai.BotTPS)
+
+	defer ticker.Stop()
+	defer c.conn.Close()
+
+	go func() {
+		done <- c.readMessages()
+	}()
+
+	for {
+		select {
+		case <-quit:
+			message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			return c.conn.WriteMessage(websocket.CloseMessage, message)
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			if err := c.sendUpdate(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *Client) sendUpdate() error {
+	c.mu.Lock()
+
+	if c.match == nil {
+		c.mu.Unlock()
+		return nil
+	}
+	point, diamondIndex := c.bot.Next(c.match)
+	u := &update{
+		PointJSON:    *model.NewPointJSON(&point),
+		DiamondIndex: diamondIndex,
+		PickupIndex:  -1,
+	}
+	c.pending = append(c.pending, time.Now())
+	c.stats.Sent++
+	c.mu.Unlock()
+
+	enc, err := json.Marshal(u)
+
+	if err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(websocket.TextMessage, enc)
+}
+
+func (c *Client) readMessages() error {
+	for {
+		_, p, err := c.conn.ReadMessage()
+
+		if err != nil {
+			return err
+		}
+		data := &responseData{}
+
+		if err := json.Unmarshal(p, data); err != nil {
+			return fmt.Errorf("read ResponseData error: %w", err)
+		}
+		if err := c.readResponse(data, time.Now()); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) readResponse(data *responseData, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Received++
+
+	switch data.Type {
+	case dataTypeGameInitialization:
+		init := &matchInit{}
+
+		if err := json.Unmarshal([]byte(data.Body), init); err != nil {
+			return fmt.Errorf("match read error: %w", err)
+		}
+		c.match = init.MatchJSON.ToMatch()
+		c.bot.SetMatch(c.match, init.Spawn)
+		c.stats.Matches++
+	case dataTypeUpdate:
+		u := &update{}
+
+		if err := json.Unmarshal([]byte(data.Body), u); err != nil {
+			return fmt.Errorf("update read error: %w", err)
+		}
+		if u.Id == c.id {
+			c.readOwnUpdate(u, now)
+			return nil
+		}
+		if u.StunnedId == c.id {
+			c.bot.Stun(int(u.Stun.Seconds() * ai.BotTPS))
+		}
+		if c.match != nil && u.DiamondIndex >= 0 && u.DiamondIndex < len(c.match.Diamonds) {
+			c.match.Diamonds = append(c.match.Diamonds[:u.DiamondIndex], c.match.Diamonds[u.DiamondIndex+1:]...)
+		}
+	case dataTypeDiamondSpawn:
+		if c.match == nil {
+			return nil
+		}
+		spawn := &diamondSpawn{}
+
+		if err := json.Unmarshal([]byte(data.Body), spawn); err != nil {
+			return fmt.Errorf("diamond spawn read error: %w", err)
+		}
+		for _, diamondJSON := range spawn.DiamondsJSON {
+			c.match.Diamonds = append(c.match.Diamonds, diamondJSON.ToDiamond())
+		}
+	}
+	return nil
+}
+
+// readOwnUpdate takes the echo of an update sent by this client, which is
+// how long the server took to handle it.
+func (c *Client) readOwnUpdate(u *update, now time.Time) {
+	if len(c.pending) > 0 {
+		c.stats.Latencies = append(c.stats.Latencies, now.Sub(c.pending[0]))
+		c.pending = c.pending[1:]
+	}
+	if u.DiamondIndex >= 0 {
+		c.stats.Diamonds++
+	}
+
+	// The server rejected the move
+	if u.Blocked {
+		c.bot.SetPosition(*u.PointJSON.ToPoint())
+	}
+}
+
+// Dial connects to the server at the given address and joins with the given
+// name. The bot playing for the client takes its choices from the seed.
+func Dial(addr string, name string, seed int64, config ai.BotConfig) (*Client, error) {
+	u := url.URL{Scheme: "ws", Host: addr, Path: ""}
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+	id, err := join(conn, name)
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Client{
+		conn: conn,
+		id:   id,
+		bot:  ai.NewBot(seed, config),
+	}, nil
+}
+
+// join sends the player name and waits for the server to accept it, it
+// returns the player id.
+func join(conn *websocket.Conn, name string) (int, error) {
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(name)); err != nil {
+		return 0, err
+	}
+	_, p, err := conn.ReadMessage()
+
+	if err != nil {
+		return 0, err
+	}
+	data := &responseData{}
+
+	if err := json.Unmarshal(p, data); err != nil {
+		return 0, fmt.Errorf("read ResponseData error: %w", err)
+	}
+	if data.Type != dataTypeJoinAccepted {
+		return 0, ErrNotAccepted
+	}
+	accepted := &joinAccepted{}
+
+	if err := json.Unmarshal([]byte(data.Body), accepted); err != nil {
+		return 0, fmt.Errorf("JoinAccepted read error: %w", err)
+	}
+	return accepted.Id, nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package headless
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"server/ai"
+	"server/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// echoServer accepts one player into a match and sends each of its updates
+// back, as the hub does.
+func echoServer(t *testing.T, match *model.Match) *httptest.Server {
+	upgrader := &websocket.Upgrader{}
+	send := func(conn *websocket.Conn, dataType int, body interface{}) error {
+		enc, err := json.Marshal(body)
+
+		if err != nil {
+			return err
+		}
+		return conn.WriteJSON(&responseData{Type: dataType, Body: string(enc)})
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+
+		if err != nil {
+			t.Error("FAILED to upgrade:", err)
+			return
+		}
+		defer conn.Close()
+
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		if err := send(conn, dataTypeJoinAccepted, &joinAccepted{Id: 7}); err != nil {
+			return
+		}
+		init := &matchInit{MatchJSON: model.NewMatchJSON(match)}
+
+		if err := send(conn, dataTypeGameInitialization, init); err != nil {
+			return
+		}
+		for {
+			_, p, err := conn.ReadMessage()
+
+			if err != nil {
+				return
+			}
+			u := &update{}
+
+			if err := json.Unmarshal(p, u); err != nil {
+				t.Error("FAILED to read update:", err)
+				return
+			}
+			u.Id = 7
+
+			if err := send(conn, dataTypeUpdate, u); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func TestClientMeasuresLatency(t *testing.T) {
+	match, err := ai.NewRandomMatch(1, ai.DefaultMatchConfig)
+
+	if err != nil {
+		t.Fatal("FAILED to generate match:", err)
+	}
+	server := echoServer(t, match)
+	defer server.Close()
+
+	client, err := Dial(strings.TrimPrefix(server.URL, "http://"), "Load", 1, ai.BotDifficulties[ai.HardBot])
+
+	if err != nil {
+		t.Fatal("FAILED to dial:", err)
+	}
+	if client.Id() != 7 {
+		t.Fatal("FAILED to read the player id")
+	}
+	quit := make(chan struct{})
+	time.AfterFunc(500*time.Millisecond, func() { close(quit) })
+
+	if err := client.Run(quit); err != nil {
+		t.Fatal("FAILED to play:", err)
+	}
+	stats := client.Stats()
+
+	if stats.Matches != 1 || stats.Sent == 0 || len(stats.Latencies) == 0 {
+		t.Fatalf("FAILED to gather stats: %+v", stats)
+	}
+	if stats.Percentile(1) < stats.Percentile(0) {
+		t.Fatal("FAILED percentiles must grow")
+	}
+}
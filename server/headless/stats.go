@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package headless
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats are what a client measured while playing.
+//
+// Sent and Received count the messages of each way, Matches the matches
+// played and Diamonds the ones the server scored. Latencies are the round
+// trips of the updates echoed back by the server.
+type Stats struct {
+	Sent      int
+	Received  int
+	Matches   int
+	Diamonds  int
+	Latencies []time.Duration
+}
+
+// Add sums the stats of another client to these ones.
+func (s *Stats) Add(other Stats) {
+	s.Sent += other.Sent
+	s.Received += other.Received
+	s.Matches += other.Matches
+	s.Diamonds += other.Diamonds
+	s.Latencies = append(s.Latencies, other.Latencies...)
+}
+
+// MeanLatency returns the average round trip, or zero if there's none.
+func (s *Stats) MeanLatency() time.Duration {
+	if len(s.Latencies) == 0 {
+		return 0
+	}
+	var sum time.Duration
+
+	for _, latency := range s.Latencies {
+		sum += latency
+	}
+	return sum / time.Duration(len(s.Latencies))
+}
+
+// Percentile returns the round trip the given fraction of them are under, or
+// zero if there's none.
+func (s *Stats) Percentile(p float64) time.Duration {
+	if len(s.Latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), s.Latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	i := int(p * float64(len(sorted)-1))
+
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
@@ -10,31 +10,53 @@ import (
 	"log"
 	"server/ai"
 	"server/model"
+	"sort"
 	"strconv"
 	"time"
 )
 
 const matchDuration = 45 * time.Second
 
+// spawnCheckInterval is how often the hub checks whether diamonds respawn.
+const spawnCheckInterval = 250 * time.Millisecond
+
+// intermission is the time between the end of a match and the next one,
+// while players look at the results.
+const intermission = 10 * time.Second
+
+// maxGenerationTries is the number of seeds tried to generate a valid match
+// before giving up.
+const maxGenerationTries = 10
+
 type Hub struct {
 	clients    map[int]*Client
 	register   chan *Client
 	unregister chan *Client
+	updates    chan *clientUpdate
+	status     chan chan *ServerStatus
 	broadcast  chan *ResponseData
 	quit       chan struct{}
 	match      *model.Match
+	seed       int64
+	fixedSeed  int64
+	config     ai.MatchConfig
+	rules      Rules
 	startTime  time.Time
+	duration   time.Duration
+	spawner    *ai.Spawner
+	pickups    *ai.PickupSpawner
+	spawns     []int
 }
 
-func (h *Hub) Start() {
-	var broadcast = func(message *ResponseData) {
-		for _, client := range h.clients {
-			client.ch <- message
-		}
-	}
+// clientUpdate is an update a client sent, for the hub to apply it.
+type clientUpdate struct {
+	client *Client
+	update *Update
+}
 
+func (h *Hub) Start() {
 	var register = func(client *Client) {
-		remainingTime := matchDuration - time.Since(h.startTime)
+		remainingTime := h.remainingTime()
 
 		var players []*PlayerJoin
 
@@ -44,10 +66,13 @@ func (h *Hub) Start() {
 				Name:      client.name,
 				PointJSON: client.PointJSON,
 				Score:     client.Score, // Send the other player score the first time
+				Bot:       client.bot,
+				Ready:     client.placed,
 			})
 		}
 
-		client.InitGame(h.match, remainingTime, players)
+		client.spawn = h.nextSpawn()
+		client.InitGame(h.newMatchInit(client, remainingTime, players))
 
 		h.push(client)
 
@@ -55,9 +80,10 @@ func (h *Hub) Start() {
 			Id:        client.id,
 			Name:      client.name,
 			PointJSON: client.PointJSON,
+			Bot:       client.bot,
 		}
 		enc, _ := json.Marshal(join)
-		broadcast(&ResponseData{
+		h.send(&ResponseData{
 			Type: DataTypePlayerJoin,
 			Body: string(enc),
 		})
@@ -66,40 +92,82 @@ func (h *Hub) Start() {
 
 	var unregister = func(client *Client) {
 		h.delete(client)
-		broadcast(&ResponseData{
+		h.send(&ResponseData{
 			Type: DataTypePlayerLeft,
 			Body: strconv.Itoa(client.id),
 		})
 	}
 
-	h.init()
+	var spawnPickup = func(now time.Time) {
+		pickup := h.pickups.Next(h.match, now)
 
-	go func() {
-		for {
-			time.Sleep(matchDuration)
-			h.init()
-			matchJSON := model.NewMatchJSON(h.match)
-			matchInit := &MatchInit{
-				MatchJSON:     matchJSON,
-				RemainingTime: matchDuration,
-			}
-			enc, err := json.Marshal(matchInit)
+		if pickup == nil {
+			return
+		}
+		h.match.Pickups = append(h.match.Pickups, pickup)
+		spawn := &PickupSpawn{[]*model.PickupJSON{model.NewPickupJSON(pickup)}}
+		enc, _ := json.Marshal(spawn)
+		h.send(&ResponseData{
+			Type: DataTypePickupSpawn,
+			Body: string(enc),
+		})
+	}
 
-			if err != nil {
-				log.Println("New match error:", err)
-				return
+	var expireEffects = func(now time.Time) {
+		for _, client := range h.clients {
+			for kind, end := range client.effects {
+				if end.IsZero() || now.Before(end) {
+					continue
+				}
+				client.effects[kind] = time.Time{}
+				expired := &EffectExpired{client.id, model.PickupKind(kind)}
+				enc, _ := json.Marshal(expired)
+				h.send(&ResponseData{
+					Type: DataTypeEffectExpired,
+					Body: string(enc),
+				})
 			}
+		}
+	}
 
-			for _, client := range h.clients {
-				client.Score = 0
-			}
+	var spawn = func(now time.Time) {
+		spawnPickup(now)
+		expireEffects(now)
+		diamonds, err := h.spawner.Next(h.match, now)
 
-			broadcast(&ResponseData{
-				Type: DataTypeGameInitialization,
-				Body: string(enc),
-			})
+		if err != nil {
+			log.Println("Diamond spawn error:", err)
+			return
+		}
+		if len(diamonds) == 0 {
+			return
 		}
-	}()
+		h.match.Diamonds = append(h.match.Diamonds, diamonds...)
+		spawn := &DiamondSpawn{}
+
+		for _, diamond := range diamonds {
+			spawn.DiamondsJSON = append(spawn.DiamondsJSON, model.NewDiamondJSON(diamond))
+		}
+		enc, _ := json.Marshal(spawn)
+		h.send(&ResponseData{
+			Type: DataTypeDiamondSpawn,
+			Body: string(enc),
+		})
+	}
+
+	if err := h.init(); err != nil {
+		log.Fatal("Unable to generate the first match: ", err)
+	}
+	spawnTicker := time.NewTicker(spawnCheckInterval)
+
+	defer spawnTicker.Stop()
+
+	// The match ends when its time is up, and the next one starts after the
+	// intermission
+	matchTimer := time.NewTimer(h.remainingTime())
+	ended := false
+
+	defer matchTimer.Stop()
 
 	for {
 		select {
@@ -108,7 +176,26 @@ func (h *Hub) Start() {
 		case client := <-h.unregister:
 			unregister(client)
 		case message := <-h.broadcast:
-			broadcast(message)
+			h.send(message)
+		case u := <-h.updates:
+			h.update(u.client, u.update)
+		case reply := <-h.status:
+			reply <- h.getStatus()
+		case now := <-spawnTicker.C:
+			spawn(now)
+		case <-matchTimer.C:
+			switch {
+			case ended:
+				ended = false
+				h.restart()
+			case h.remainingTime() <= 0:
+				// Time bonus diamonds extend the match, otherwise it's over
+				ended = true
+				h.sendMatchEnd()
+				matchTimer.Reset(intermission)
+				continue
+			}
+			matchTimer.Reset(h.remainingTime())
 		case <-h.quit:
 			log.Println("Hub QUIT")
 			return
@@ -116,19 +203,198 @@ func (h *Hub) Start() {
 	}
 }
 
+// send sends the message to every client, from the hub goroutine.
+func (h *Hub) send(message *ResponseData) {
+	for _, client := range h.clients {
+		client.ch <- message
+	}
+}
+
 func (h *Hub) Register(c *Client) {
 	log.Printf("Client %s (%d) connected.\n", c.name, c.id)
 	h.register <- c
 }
 
+// Status returns how many players are in the hub and the time left of the
+// current match.
+func (h *Hub) Status() *ServerStatus {
+	reply := make(chan *ServerStatus)
+	h.status <- reply
+	return <-reply
+}
+
+func (h *Hub) getStatus() *ServerStatus {
+	status := &ServerStatus{RemainingTime: h.remainingTime()}
+
+	for _, client := range h.clients {
+		if client.bot {
+			status.Bots++
+		} else {
+			status.Players++
+		}
+	}
+	return status
+}
+
 func (h *Hub) Unregister(c *Client) {
 	log.Printf("Client %s (%d) disconnected.\n", c.name, c.id)
 	h.unregister <- c
 }
 
-func (h *Hub) init() {
-	h.match = ai.NewRandomMatch()
-	h.startTime = time.Now()
+func (h *Hub) init() error {
+	var err error
+
+	for i := 0; i < maxGenerationTries; i++ {
+		var match *model.Match
+		seed := h.nextSeed()
+		match, err = ai.NewRandomMatch(seed, h.config)
+
+		if err == nil {
+			report := ai.Validate(match)
+			err = report.Err()
+
+			if err == nil {
+				h.seed = seed
+				h.match = match
+				h.startTime = time.Now()
+				h.duration = matchDuration
+				h.spawner = ai.NewSpawner(seed, h.config, match, h.startTime)
+				h.pickups = ai.NewPickupSpawner(seed, h.config, match, h.startTime)
+				h.spawns = ai.LayoutSpawns(match.Dungeons, match.Paths)
+
+				log.Printf("New match generated from seed %d: %+v\n", seed, report.Metrics)
+				return nil
+			}
+		}
+		log.Printf("Failed to generate match from seed %d: %v\n", seed, err)
+
+		if h.fixedSeed != 0 {
+			break
+		}
+	}
+	return err
+}
+
+func (h *Hub) newMatchInit(client *Client, remainingTime time.Duration, players []*PlayerJoin) *MatchInit {
+	return &MatchInit{
+		MatchJSON:     model.NewMatchJSON(h.match),
+		Seed:          h.seed,
+		Config:        h.config,
+		Spawn:         client.spawn,
+		RemainingTime: remainingTime,
+		Players:       players,
+	}
+}
+
+// nextSpawn returns the spawn dungeon shared by the fewest players.
+func (h *Hub) nextSpawn() int {
+	counts := make(map[int]int)
+
+	for _, client := range h.clients {
+		counts[client.spawn]++
+	}
+	spawn := h.spawns[0]
+
+	for _, s := range h.spawns {
+		if counts[s] < counts[spawn] {
+			spawn = s
+		}
+	}
+	return spawn
+}
+
+// restart generates the next match and sends it to every client, with the
+// scores and stats cleared and new spawns.
+func (h *Hub) restart() {
+	if err := h.init(); err != nil {
+		log.Println("New match error:", err)
+		return
+	}
+	for _, client := range h.clients {
+		client.spawn = -1
+	}
+	for _, client := range h.clients {
+		client.Score = 0
+		client.stats = matchStats{}
+		client.spawn = h.nextSpawn()
+		enc, err := json.Marshal(h.newMatchInit(client, matchDuration, nil))
+
+		if err != nil {
+			log.Println("New match error:", err)
+			return
+		}
+		client.ch <- &ResponseData{
+			Type: DataTypeGameInitialization,
+			Body: string(enc),
+		}
+	}
+}
+
+// sendMatchEnd sends every client the results of the match.
+func (h *Hub) sendMatchEnd() {
+	var results []*PlayerResult
+
+	for _, client := range h.clients {
+		results = append(results, &PlayerResult{
+			Id:          client.id,
+			Name:        client.name,
+			Bot:         client.bot,
+			Score:       client.Score,
+			Diamonds:    client.stats.diamonds,
+			Distance:    client.stats.distance,
+			FirstPickup: client.stats.firstPickup,
+		})
+	}
+	rankResults(results)
+	enc, err := json.Marshal(&MatchEnd{Results: results, Intermission: intermission})
+
+	if err != nil {
+		log.Println("Match end error:", err)
+		return
+	}
+	for _, client := range h.clients {
+		client.ch <- &ResponseData{
+			Type: DataTypeMatchEnd,
+			Body: string(enc),
+		}
+	}
+}
+
+// rankResults sorts the results from the highest score, and the most
+// diamonds and the earliest first pickup among the same score.
+func rankResults(results []*PlayerResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a := results[i]
+		b := results[j]
+
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Diamonds != b.Diamonds {
+			return a.Diamonds > b.Diamonds
+		}
+		return a.FirstPickup < b.FirstPickup
+	})
+	for i, result := range results {
+		result.Rank = i + 1
+
+		if i > 0 && result.Score == results[i-1].Score {
+			result.Rank = results[i-1].Rank
+		}
+	}
+}
+
+func (h *Hub) remainingTime() time.Duration {
+	return h.duration - time.Since(h.startTime)
+}
+
+// nextSeed returns the fixed seed if any was given to the hub, so every match
+// is the same map, or a new random seed otherwise.
+func (h *Hub) nextSeed() int64 {
+	if h.fixedSeed != 0 {
+		return h.fixedSeed
+	}
+	return time.Now().UnixNano()
 }
 
 func (h *Hub) push(client *Client) {
@@ -139,12 +405,129 @@ func (h *Hub) delete(client *Client) {
 	delete(h.clients, client.id)
 }
 
-func (h *Hub) listen(client *Client) {
-	remove := func(slice []*model.Diamond, s int) []*model.Diamond {
-		return append(slice[:s], slice[s+1:]...)
+// takeDiamond removes the diamond at the given index, if any, and scores it
+// for the client from the scoring table. It returns the index of the diamond
+// taken, or -1 if there's no such a diamond, the runner doesn't touch it or
+// the match is over. The index is stale when the diamonds changed since the
+// client saw them.
+func (h *Hub) takeDiamond(client *Client, index int, runner *model.Runner) int {
+	if index < 0 || index >= len(h.match.Diamonds) || h.remainingTime() <= 0 {
+		return -1
+	}
+	if !runner.CheckDiamondCollision(h.match.Diamonds[index]) {
+		return -1
+	}
+	value := h.match.Diamonds[index].Value()
+	h.match.Diamonds = append(h.match.Diamonds[:index], h.match.Diamonds[index+1:]...)
+	client.Score += value.Points
+	client.stats.diamonds++
+
+	if client.stats.diamonds == 1 {
+		client.stats.firstPickup = time.Since(h.startTime)
+	}
+	h.duration += value.Time
+	h.spawner.Taken(time.Now())
+	return index
+}
+
+// takePickup removes the pickup at the given index, if any, and applies its
+// effect to the client. It returns the index of the pickup taken, or -1 if
+// there's no such a pickup, the runner doesn't touch it or the match is over.
+func (h *Hub) takePickup(client *Client, index int, runner *model.Runner) int {
+	if index < 0 || index >= len(h.match.Pickups) || h.remainingTime() <= 0 {
+		return -1
+	}
+	if !runner.CheckPickupCollision(h.match.Pickups[index]) {
+		return -1
+	}
+	pickup := h.match.Pickups[index]
+	h.match.Pickups = append(h.match.Pickups[:index], h.match.Pickups[index+1:]...)
+	client.effects[pickup.Kind()] = time.Now().Add(pickup.Duration())
+	return index
+}
+
+// collide blocks the move of the update if the client is stunned or if it
+// bumps into another runner, who gets stunned and loses a diamond unless it
+// was stunned already or has a shield. It's called from the hub goroutine,
+// since it changes other clients and the match.
+func (h *Hub) collide(client *Client, update *Update) {
+	now := time.Now()
+
+	if now.Before(client.stunned) {
+		update.Blocked = true
+		update.PointJSON = client.PointJSON
+		return
+	}
+	runner := getRunnerAt(update.PointJSON)
+	last := getRunnerAt(client.PointJSON)
+
+	for _, other := range h.clients {
+		if other == client || !other.placed {
+			continue
+		}
+		otherRunner := getRunnerAt(other.PointJSON)
+
+		// Runners spawned on top of each other can walk apart
+		if !runner.Collides(&otherRunner) || (client.placed && last.Collides(&otherRunner)) {
+			continue
+		}
+		update.Blocked = true
+		update.PointJSON = client.PointJSON
+
+		if now.Before(other.stunned) || now.Before(other.effects[model.PickupShield]) {
+			return
+		}
+		other.stunned = now.Add(h.rules.Stun)
+		update.StunnedId = other.id
+		update.Stun = h.rules.Stun
+		h.knockLoose(other)
+		return
 	}
+}
+
+// knockLoose takes a common diamond from the client and drops it somewhere in
+// the dungeon it's in.
+func (h *Hub) knockLoose(client *Client) {
+	value := model.DiamondValues[model.DiamondCommon].Points
+
+	// Checked first so a runner with nothing to lose doesn't use up the rng
+	if client.Score < value {
+		return
+	}
+	diamond := h.spawner.Loose(h.match, *client.PointJSON.ToPoint())
+
+	if diamond == nil {
+		return
+	}
+	client.Score -= value
+	h.match.Diamonds = append(h.match.Diamonds, diamond)
+	spawn := &DiamondSpawn{[]*model.DiamondJSON{model.NewDiamondJSON(diamond)}}
+	enc, _ := json.Marshal(spawn)
+	h.send(&ResponseData{
+		Type: DataTypeDiamondSpawn,
+		Body: string(enc),
+	})
+}
+
+// runnerOf returns the runner of the client at the given point, with its
+// magnet if it has one so it reaches as far as in the game.
+func (h *Hub) runnerOf(client *Client, point model.PointJSON) model.Runner {
+	runner := getRunnerAt(point)
+
+	if time.Now().Before(client.effects[model.PickupMagnet]) {
+		runner.ApplyEffect(model.PickupMagnet, 1)
+	}
+	return runner
+}
+
+func getRunnerAt(point model.PointJSON) model.Runner {
+	runner := model.NewRunner()
+	runner.SetPosition(point.X, point.Y)
+	return runner
+}
+
+func (h *Hub) listen(client *Client) {
 	conn := client.conn
-	id := client.id
 
 	for {
 		_, p, err := conn.ReadMessage()
@@ -163,35 +546,62 @@ func (h *Hub) listen(client *Client) {
 			log.Println("Parse update error:", err)
 			continue
 		}
-		update.Id = id
-		client.PointJSON = update.PointJSON
-		enc, err := json.Marshal(update)
+		h.updates <- &clientUpdate{client, update}
+	}
+}
 
-		if err != nil {
-			log.Println("Encode update error:", err)
-			continue
-		}
+// update applies the update of the client to the match and sends it to
+// every client. The hub goroutine is the only one that changes the match
+// and the clients.
+func (h *Hub) update(client *Client, update *Update) {
+	update.Id = client.id
+	update.StunnedId = -1
 
-		if update.DiamondIndex != -1 && update.DiamondIndex < len(h.match.Diamonds) {
-			h.match.Diamonds = remove(h.match.Diamonds, update.DiamondIndex)
-			client.Score += 30
-		} else {
-			update.DiamondIndex = -1
-		}
+	if h.rules.Collision {
+		h.collide(client, update)
+	}
+	runner := h.runnerOf(client, update.PointJSON)
+	update.DiamondIndex = h.takeDiamond(client, update.DiamondIndex, &runner)
+	update.PickupIndex = h.takePickup(client, update.PickupIndex, &runner)
+	update.Score = client.Score
+	update.RemainingTime = h.remainingTime()
 
-		h.broadcast <- &ResponseData{
-			Type: DataTypeUpdate,
-			Body: string(enc),
-		}
+	if client.placed {
+		client.stats.move(client.PointJSON, update.PointJSON)
+	}
+	client.PointJSON = update.PointJSON
+
+	// The first move tells that the player left the lobby
+	if !client.placed {
+		client.placed = true
+		h.send(&ResponseData{
+			Type: DataTypePlayerReady,
+			Body: strconv.Itoa(client.id),
+		})
+	}
+	enc, err := json.Marshal(update)
+
+	if err != nil {
+		log.Println("Encode update error:", err)
+		return
 	}
+	h.send(&ResponseData{
+		Type: DataTypeUpdate,
+		Body: string(enc),
+	})
 }
 
-func NewHub(ch chan *ResponseData, quit chan struct{}) *Hub {
+func NewHub(ch chan *ResponseData, quit chan struct{}, seed int64, config ai.MatchConfig, rules Rules) *Hub {
 	return &Hub{
 		clients:    make(map[int]*Client),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		updates:    make(chan *clientUpdate),
+		status:     make(chan chan *ServerStatus),
 		broadcast:  ch,
 		quit:       quit,
+		fixedSeed:  seed,
+		config:     config,
+		rules:      rules,
 	}
 }
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"server/ai"
 	"server/model"
 	"time"
 )
@@ -16,6 +17,11 @@ const (
 	DataTypeJoinAccepted       = 3
 	DataTypePlayerJoin         = 4
 	DataTypePlayerLeft         = 5
+	DataTypeDiamondSpawn       = 6
+	DataTypePickupSpawn        = 7
+	DataTypeEffectExpired      = 8
+	DataTypePlayerReady        = 9
+	DataTypeMatchEnd           = 10
 )
 
 type ResponseData struct {
@@ -23,26 +29,107 @@ type ResponseData struct {
 	Body string
 }
 
+// MatchInit starts a match for a player, who spawns at the dungeon with the
+// Spawn index.
 type MatchInit struct {
 	MatchJSON     *model.MatchJSON
+	Seed          int64
+	Config        ai.MatchConfig
+	Spawn         int
 	RemainingTime time.Duration
 	Players       []*PlayerJoin
 }
 
+// DiamondSpawn announces the diamonds respawned in the current match, they
+// go after the diamonds left.
+type DiamondSpawn struct {
+	DiamondsJSON []*model.DiamondJSON
+}
+
+// PickupSpawn announces the pickups spawned in the current match, they go
+// after the pickups left.
+type PickupSpawn struct {
+	PickupsJSON []*model.PickupJSON
+}
+
+// EffectExpired announces that the effect of a pickup of the given kind is
+// over for the player with the given id.
+type EffectExpired struct {
+	Id   int
+	Kind model.PickupKind
+}
+
 type JoinAccepted struct {
 	Id int
 }
 
+// PlayerJoin announces a player, Bot tells that it's played by the server.
+// Ready tells that it left the lobby and is playing the match, until then
+// it's announced by a PlayerReady message with its id.
 type PlayerJoin struct {
 	Id        int
 	Name      string
 	PointJSON model.PointJSON
 	Score     int
+	Bot       bool
+	Ready     bool
+}
+
+// MatchEnd announces the results of the match that just ended, ranked from
+// the winner. The next match starts after the Intermission.
+type MatchEnd struct {
+	Results      []*PlayerResult
+	Intermission time.Duration
 }
 
+// PlayerResult is how a player did in a match. Players with the same score
+// share the Rank. Distance is how far its runner walked, and FirstPickup the
+// time until its first diamond, zero if it took none.
+type PlayerResult struct {
+	Id          int
+	Name        string
+	Bot         bool
+	Rank        int
+	Score       int
+	Diamonds    int
+	Distance    int
+	FirstPickup time.Duration
+}
+
+// ServerStatus is what the server browser of the game shows about the hub.
+type ServerStatus struct {
+	Players       int
+	Bots          int
+	RemainingTime time.Duration
+}
+
+// Update is the position a player sends on each frame. The server fills
+// the player score and the remaining time, as it's the one scoring diamonds.
+//
+// PickupIndex is the pickup taken like DiamondIndex, its effect is applied
+// to the player until an EffectExpired arrives.
+//
+// With collisions on, Blocked tells that the server rejected the move so the
+// position is the last one allowed, and StunnedId is the player the move
+// bumped into, who is stunned for Stun, or -1 if there's none.
 type Update struct {
 	Id int
 	//Move int // use point for now
-	PointJSON    model.PointJSON
-	DiamondIndex int
+	PointJSON     model.PointJSON
+	DiamondIndex  int
+	PickupIndex   int
+	Score         int
+	RemainingTime time.Duration
+	Blocked       bool
+	StunnedId     int
+	Stun          time.Duration
+}
+
+// Rules are the gameplay options of the hub.
+//
+// Collision makes runners block each other. A runner bumping into another one
+// stuns it for Stun and knocks loose one of its common diamonds.
+type Rules struct {
+	Collision bool
+	Stun      time.Duration
 }
@@ -4,13 +4,56 @@
 
 package model
 
+import "time"
+
 const (
 	DiamondWidthPx  = 32
 	DiamondHeightPx = 26
 )
 
+const (
+	DiamondCommon    DiamondKind = 0
+	DiamondRare      DiamondKind = 1
+	DiamondCursed    DiamondKind = 2
+	DiamondTimeBonus DiamondKind = 3
+)
+
+// DiamondKinds is the number of kinds of diamonds.
+const DiamondKinds = 4
+
+// DiamondValues is the scoring table, what a runner gets for picking up each
+// kind of diamond.
+var DiamondValues = [DiamondKinds]DiamondValue{
+	DiamondCommon:    {Points: 30},
+	DiamondRare:      {Points: 100},
+	DiamondCursed:    {Points: -50},
+	DiamondTimeBonus: {Points: 10, Time: 5 * time.Second},
+}
+
+type DiamondKind int
+
+// DiamondValue is the score a diamond adds and the time it extends the
+// match by.
+type DiamondValue struct {
+	Points int
+	Time   time.Duration
+}
+
 type Diamond struct {
 	rect Rect
+	kind DiamondKind
+}
+
+func (d *Diamond) Rect() Rect {
+	return d.rect
+}
+
+func (d *Diamond) Kind() DiamondKind {
+	return d.kind
+}
+
+func (d *Diamond) Value() DiamondValue {
+	return DiamondValues[d.kind]
 }
 
 func (d *Diamond) Collides(rect *Rect) bool {
@@ -18,6 +61,13 @@ func (d *Diamond) Collides(rect *Rect) bool {
 }
 
 func NewDiamond(point Point) Diamond {
+	return NewDiamondOfKind(point, DiamondCommon)
+}
+
+func NewDiamondOfKind(point Point, kind DiamondKind) Diamond {
+	if kind < 0 || kind >= DiamondKinds {
+		panic("Invalid diamond kind")
+	}
 	rect := Rect{
 		left:   point.X(),
 		top:    point.Y(),
@@ -26,19 +76,21 @@ func NewDiamond(point Point) Diamond {
 	}
 	return Diamond{
 		rect: rect,
+		kind: kind,
 	}
 }
 
 type DiamondJSON struct {
 	*PointJSON
+	Kind DiamondKind
 }
 
 func (d *DiamondJSON) ToDiamond() *Diamond {
-	diamond := NewDiamond(*d.PointJSON.ToPoint())
+	diamond := NewDiamondOfKind(*d.PointJSON.ToPoint(), d.Kind)
 	return &diamond
 }
 
 func NewDiamondJSON(d *Diamond) *DiamondJSON {
 	point := &Point{d.rect.left, d.rect.top}
-	return &DiamondJSON{NewPointJSON(point)}
+	return &DiamondJSON{NewPointJSON(point), d.kind}
 }
@@ -0,0 +1,22 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiamondJSON(t *testing.T) {
+	diamond := NewDiamondOfKind(NewPoint(10, 20), DiamondCursed)
+	json := NewDiamondJSON(&diamond)
+
+	if !reflect.DeepEqual(json.ToDiamond(), &diamond) {
+		t.Fatal("FAILED diamond kind must be kept by JSON")
+	}
+	if diamond.Value().Points >= 0 {
+		t.Fatal("FAILED cursed diamonds must take points")
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDungeonDoors(t *testing.T) {
+	factor := DimensionFactor{Width: 2, Height: 2}
+	dungeon := NewDungeon(NewPoint(100, 100), factor)
+	other := NewDungeon(NewPoint(400, 100), factor)
+	path := dungeon.GetPathFor(&other)
+
+	// Runner inside the dungeon, next to the right wall and aligned to the path
+	rect := NewRect(183, 148, 215, 180)
+	right := Movement{MoveDirRight, 1}
+
+	if dungeon.CanMoveTowards(right, &rect) {
+		t.Fatal("FAILED must not walk through a wall without doors")
+	}
+
+	dungeon.AddDoor(path)
+
+	if len(dungeon.barrier.Doors()) != 1 {
+		t.Fatal("FAILED the path crosses a single wall")
+	}
+	if !dungeon.CanMoveTowards(right, &rect) {
+		t.Fatal("FAILED must walk through the door")
+	}
+
+	rect = NewRect(183, 110, 215, 142)
+
+	if dungeon.CanMoveTowards(right, &rect) {
+		t.Fatal("FAILED must not walk through the wall next to the door")
+	}
+
+	json := NewDungeonJSON(&dungeon)
+
+	if !reflect.DeepEqual(json.ToDungeon(), &dungeon) {
+		t.Fatal("FAILED doors must be kept by JSON")
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package model
+
+const (
+	HazardSizePx = 48
+
+	// SpikeStunFrames is how long spikes stun the runner stepping on them.
+	SpikeStunFrames = 60
+
+	// CollapseFrames is how long a runner can stand on a collapsing floor
+	// before it gives way.
+	CollapseFrames = 90
+
+	// FallStunFrames is how long a runner is stunned after falling through a
+	// collapsing floor.
+	FallStunFrames = 45
+)
+
+const (
+	HazardSpikes   HazardKind = 0
+	HazardSlow     HazardKind = 1
+	HazardTeleport HazardKind = 2
+	HazardCollapse HazardKind = 3
+)
+
+// HazardKinds is the number of kinds of hazards.
+const HazardKinds = 4
+
+// HazardKind is what a hazard does to the runner standing on it: spikes stun
+// it, slow zones halve its speed, teleport pads send it to their target and
+// collapsing floors drop it back to the center of the dungeon if it stands on
+// them for too long.
+type HazardKind int
+
+// Hazard is a trap lying on the floor of a dungeon.
+type Hazard struct {
+	rect   Rect
+	kind   HazardKind
+	target Point
+}
+
+func (h *Hazard) Rect() Rect {
+	return h.rect
+}
+
+func (h *Hazard) Kind() HazardKind {
+	return h.kind
+}
+
+// Target returns the position a teleport pad sends runners to.
+func (h *Hazard) Target() Point {
+	return h.target
+}
+
+// IsUnder tells whether the runner rect stands on the hazard, that is its
+// center is within it.
+func (h *Hazard) IsUnder(rect *Rect) bool {
+	center := rect.Center()
+	return h.rect.Contains(&center)
+}
+
+func NewHazard(point Point, kind HazardKind, target Point) Hazard {
+	if kind < 0 || kind >= HazardKinds {
+		panic("Invalid hazard kind")
+	}
+	rect := Rect{
+		left:   point.X(),
+		top:    point.Y(),
+		right:  point.X() + HazardSizePx,
+		bottom: point.Y() + HazardSizePx,
+	}
+	return Hazard{
+		rect:   rect,
+		kind:   kind,
+		target: target,
+	}
+}
+
+type HazardJSON struct {
+	*PointJSON
+	Kind       HazardKind
+	TargetJSON *PointJSON
+}
+
+func (h *HazardJSON) ToHazard() *Hazard {
+	hazard := NewHazard(*h.PointJSON.ToPoint(), h.Kind, *h.TargetJSON.ToPoint())
+	return &hazard
+}
+
+func NewHazardJSON(h *Hazard) *HazardJSON {
+	point := &Point{h.rect.left, h.rect.top}
+	return &HazardJSON{NewPointJSON(point), h.kind, NewPointJSON(&h.target)}
+}
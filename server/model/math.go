@@ -156,6 +156,27 @@ func (r *Rect) Intersects(rect *Rect) bool {
 	return xi && yi
 }
 
+func (r *Rect) Contains(point *Point) bool {
+	return r.left <= point.X() &&
+		r.top <= point.Y() &&
+		r.right >= point.X() &&
+		r.bottom >= point.Y()
+}
+
+// Overlap returns the area shared by both rects, and false if they don't share
+// any area.
+func (r *Rect) Overlap(rect *Rect) (Rect, bool) {
+	left := max(r.left, rect.Left())
+	top := max(r.top, rect.Top())
+	right := min(r.right, rect.Right())
+	bottom := min(r.bottom, rect.Bottom())
+
+	if left >= right || top >= bottom {
+		return Rect{}, false
+	}
+	return Rect{left, top, right, bottom}, true
+}
+
 func (r *Rect) InBounds(rect *Rect) bool {
 	return r.left <= rect.Left() &&
 		r.top <= rect.Top() &&
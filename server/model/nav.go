@@ -0,0 +1,287 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package model
+
+import "math"
+
+// Nav is the walkable graph of a match, to find the way between any two
+// points a runner can stand at.
+//
+// Its nodes are the centers of the dungeons and the corners of the paths, as
+// runner centers. Nodes within the same dungeon are connected, and so are the
+// consecutive corners of each path, as long as a runner can walk between them
+// moving horizontally first and then vertically.
+type Nav struct {
+	match   *Match
+	nodes   []Point
+	edges   [][]navEdge
+	inside  [][]int
+	corners [][]int
+}
+
+type navEdge struct {
+	to     int
+	length int
+}
+
+// Route returns the points a runner centered at from walks through to get to
+// the given point, moving horizontally first and then vertically between
+// them. The last point is the closest one to the target the runner can stand
+// at. It returns false if either point is out of the match or there's no way
+// between them.
+func (n *Nav) Route(from Point, to Point) ([]Point, bool) {
+	start, startNodes, ok := n.locate(from)
+
+	if !ok {
+		return nil, false
+	}
+	goal, goalNodes, ok := n.locate(to)
+
+	if !ok {
+		return nil, false
+	}
+	if sameNodes(startNodes, goalNodes) {
+		return []Point{goal}, true
+	}
+	distances, parents := n.distancesFrom(start, startNodes)
+	last := -1
+	best := math.MaxInt32
+
+	for _, node := range goalNodes {
+		if distances[node] == math.MaxInt32 {
+			continue
+		}
+		if d := distances[node] + manhattan(n.nodes[node], goal); d < best {
+			best = d
+			last = node
+		}
+	}
+	if last == -1 {
+		return nil, false
+	}
+	route := []Point{goal}
+
+	for node := last; node != -1; node = parents[node] {
+		route = append([]Point{n.nodes[node]}, route...)
+	}
+	return route, true
+}
+
+// Distance returns the length of the route between both points, or false if
+// there's no such a route.
+func (n *Nav) Distance(from Point, to Point) (int, bool) {
+	route, ok := n.Route(from, to)
+
+	if !ok {
+		return 0, false
+	}
+	length := 0
+
+	for _, point := range route {
+		length += manhattan(from, point)
+		from = point
+	}
+	return length, true
+}
+
+// locate returns the closest point to the given one a runner can stand at,
+// and the nodes it can walk straight to, those of its dungeon or the ends of
+// its path segment.
+func (n *Nav) locate(point Point) (Point, []int, bool) {
+	for i, dungeon := range n.match.Dungeons {
+		if dungeon.Contains(&point) {
+			return clampToFloor(dungeon, point), n.inside[i], true
+		}
+	}
+	sw := PathWidthPx / 2
+
+	for i, path := range n.match.Paths {
+		for j, line := range path.Lines() {
+			p1 := line.P1()
+			p2 := line.P2()
+
+			if line.IsHorizontal() && abs(point.Y()-p1.Y()) <= sw &&
+				point.X() >= min(p1.X(), p2.X()) && point.X() <= max(p1.X(), p2.X()) {
+				return NewPoint(point.X(), p1.Y()), n.corners[i][j : j+2], true
+			}
+			if line.IsVertical() && abs(point.X()-p1.X()) <= sw &&
+				point.Y() >= min(p1.Y(), p2.Y()) && point.Y() <= max(p1.Y(), p2.Y()) {
+				return NewPoint(p1.X(), point.Y()), n.corners[i][j : j+2], true
+			}
+		}
+	}
+	return Point{}, nil, false
+}
+
+// distancesFrom returns the walking distance from the given point to every
+// node, through the given nodes it's connected to, and the node before each
+// one on the way, or -1 for the first ones.
+func (n *Nav) distancesFrom(point Point, first []int) ([]int, []int) {
+	count := len(n.nodes)
+	distances := make([]int, count)
+	parents := make([]int, count)
+	done := make([]bool, count)
+
+	for i := range distances {
+		distances[i] = math.MaxInt32
+		parents[i] = -1
+	}
+	for _, node := range first {
+		distances[node] = manhattan(point, n.nodes[node])
+	}
+
+	// Dijkstra's algorithm, a match has a few hundred nodes at most
+	for {
+		next := -1
+
+		for i := range distances {
+			if !done[i] && distances[i] != math.MaxInt32 && (next == -1 || distances[i] < distances[next]) {
+				next = i
+			}
+		}
+		if next == -1 {
+			return distances, parents
+		}
+		done[next] = true
+
+		for _, edge := range n.edges[next] {
+			if d := distances[next] + edge.length; d < distances[edge.to] {
+				distances[edge.to] = d
+				parents[edge.to] = next
+			}
+		}
+	}
+}
+
+func (n *Nav) node(point Point, indices map[Point]int) int {
+	if i, ok := indices[point]; ok {
+		return i
+	}
+	indices[point] = len(n.nodes)
+	n.nodes = append(n.nodes, point)
+	n.edges = append(n.edges, nil)
+	return len(n.nodes) - 1
+}
+
+func (n *Nav) connect(a int, b int) {
+	if a == b || !canWalk(n.match, n.nodes[a], n.nodes[b]) {
+		return
+	}
+	length := manhattan(n.nodes[a], n.nodes[b])
+	n.edges[a] = append(n.edges[a], navEdge{b, length})
+	n.edges[b] = append(n.edges[b], navEdge{a, length})
+}
+
+// NewNav builds the walkable graph of the given match.
+func NewNav(match *Match) *Nav {
+	nav := &Nav{
+		match:   match,
+		inside:  make([][]int, len(match.Dungeons)),
+		corners: make([][]int, len(match.Paths)),
+	}
+	indices := map[Point]int{}
+
+	for i, dungeon := range match.Dungeons {
+		nav.inside[i] = append(nav.inside[i], nav.node(dungeon.Center(), indices))
+	}
+	for i, path := range match.Paths {
+		for j, point := range path.Points() {
+			node := nav.node(point, indices)
+			nav.corners[i] = append(nav.corners[i], node)
+
+			if j > 0 {
+				nav.connect(nav.corners[i][j-1], node)
+			}
+			for k, dungeon := range match.Dungeons {
+				if dungeon.Contains(&point) && !containsNode(nav.inside[k], node) {
+					nav.inside[k] = append(nav.inside[k], node)
+				}
+			}
+		}
+	}
+	for _, nodes := range nav.inside {
+		for i, a := range nodes {
+			for _, b := range nodes[i+1:] {
+				nav.connect(a, b)
+			}
+		}
+	}
+	return nav
+}
+
+// canWalk tells whether a runner centered at from can get to the given point
+// moving horizontally first and then vertically.
+func canWalk(match *Match, from Point, to Point) bool {
+	runner := NewRunner()
+	runner.SetPosition(from.X()-frameWidth/2, from.Y()-frameHeight/2)
+
+	for {
+		center := runner.Rect.Center()
+		direction := -1
+
+		switch {
+		case center.X() < to.X():
+			direction = MoveDirRight
+		case center.X() > to.X():
+			direction = MoveDirLeft
+		case center.Y() < to.Y():
+			direction = MoveDirBottom
+		case center.Y() > to.Y():
+			direction = MoveDirTop
+		}
+		if direction == -1 {
+			return true
+		}
+		match.SetCurrentDungeonAndPaths(&runner)
+
+		if runner.IsOutSide() || !runner.CanMoveTowards(direction) {
+			return false
+		}
+		runner.moveTowards(direction)
+	}
+}
+
+// clampToFloor returns the closest point to the given one a runner can be
+// centered at within the dungeon walls, without touching them.
+func clampToFloor(dungeon *Dungeon, point Point) Point {
+	rect := dungeon.rect
+	minX := rect.Left() + wallWidth + frameWidth/2 + 1
+	maxX := rect.Right() - wallWidth - frameWidth/2 - 1
+	minY := rect.Top() + wallWidth + frameHeight/2 + 1
+	maxY := rect.Bottom() - wallWidth - frameHeight/2 - 1
+	return NewPoint(max(minX, min(maxX, point.X())), max(minY, min(maxY, point.Y())))
+}
+
+func sameNodes(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsNode(nodes []int, node int) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+func manhattan(a Point, b Point) int {
+	return abs(a.X()-b.X()) + abs(a.Y()-b.Y())
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package model
+
+import "testing"
+
+func TestNavRoute(t *testing.T) {
+	d1 := NewDungeon(NewPoint(100, 100), DimensionFactor{Width: 2, Height: 2})
+	d2 := NewDungeon(NewPoint(500, 400), DimensionFactor{Width: 2, Height: 2})
+	d3 := NewDungeon(NewPoint(900, 100), DimensionFactor{Width: 2, Height: 2})
+	path := d1.GetPathFor(&d2)
+	d1.AddDoor(path)
+	d2.AddDoor(path)
+	match := &Match{
+		Dungeons: []*Dungeon{&d1, &d2, &d3},
+		Paths:    []*Path{path},
+	}
+	route, ok := match.Nav().Route(NewPoint(130, 130), NewPoint(580, 420))
+
+	if !ok {
+		t.Fatal("FAILED expected a route between connected dungeons")
+	}
+	goal := route[len(route)-1]
+
+	if !d2.Contains(&goal) {
+		t.Fatal("FAILED route must end within the target dungeon")
+	}
+	if _, ok := match.Nav().Route(d1.Center(), d3.Center()); ok {
+		t.Fatal("FAILED there's no way to a disconnected dungeon")
+	}
+	if _, ok := match.Nav().Route(d1.Center(), NewPoint(5, 5)); ok {
+		t.Fatal("FAILED there's no way out of the match")
+	}
+}
@@ -8,80 +8,113 @@ const (
 	PathWidthPx = 36
 )
 
+// Path is a corridor that follows an orthogonal polyline, so each of its
+// segments is either horizontal or vertical.
 type Path struct {
-	hLine Line
-	hRect Rect
-	vLine Line
-	vRect Rect
+	points []Point
+	lines  []Line
+	rects  []Rect
 }
 
-func (p *Path) InBounds(rect *Rect) bool {
-	return p.hRect.InBounds(rect) || p.vRect.InBounds(rect)
+func (p *Path) Points() []Point {
+	return p.points
 }
 
-func (p *Path) CanMoveTowards(movement Movement, rect *Rect) bool {
-	if !p.InBounds(rect) {
-		return true
-	}
-	mx := false
-	my := false
+func (p *Path) Lines() []Line {
+	return p.lines
+}
 
-	if p.hRect.InBounds(rect) {
-		mx = CheckMovement(movement, rect, p.hRect)
-	}
-	if p.vRect.InBounds(rect) {
-		my = CheckMovement(movement, rect, p.vRect)
+// Length returns the sum of the lengths of the path segments.
+func (p *Path) Length() int {
+	length := 0
+
+	for _, line := range p.lines {
+		length += line.Length()
 	}
-	return mx || my
+	return length
 }
 
-func NewPath(hl Line, vl Line) Path {
-	if hl.IsDegenerate() || vl.IsDegenerate() {
-		panic("Lines cannot be degenerate")
+func (p *Path) InBounds(rect *Rect) bool {
+	for _, r := range p.rects {
+		if r.InBounds(rect) {
+			return true
+		}
 	}
-	if !(hl.IsHorizontal() && vl.IsVertical()) {
-		panic("The first line must be horizontal and the other vertical")
+	return false
+}
+
+func (p *Path) Intersects(rect *Rect) bool {
+	for _, r := range p.rects {
+		if r.Intersects(rect) {
+			return true
+		}
 	}
-	if !hl.p1.Equals(&vl.p1) && !hl.p1.Equals(&vl.p2) &&
-		!hl.p2.Equals(&vl.p1) && !hl.p2.Equals(&vl.p2) {
-		panic("One of the horizontal line start/end point must be the beginning of the vertical line")
+	return false
+}
+
+func (p *Path) CanMoveTowards(movement Movement, rect *Rect) bool {
+	if !p.InBounds(rect) {
+		return true
 	}
-	if hl.p1.X() > hl.p2.X() {
-		panic("The point 1 of the horizontal line must be the lowest")
+	for _, r := range p.rects {
+		if r.InBounds(rect) && CheckMovement(movement, rect, r) {
+			return true
+		}
 	}
-	if vl.p1.Y() > vl.p2.Y() {
-		panic("The point 1 of the vertical line must be the lowest")
+	return false
+}
+
+func NewPath(points []Point) Path {
+	if len(points) < 2 {
+		panic("A path must have at least two points")
 	}
+	var lines []Line
+	var rects []Rect
 	sw := PathWidthPx / 2
-	hRect := NewRect(
-		hl.p1.X()-sw,
-		hl.p1.Y()-sw,
-		hl.p2.X()+sw,
-		hl.p1.Y()+sw,
-	)
-	vRect := NewRect(
-		vl.p1.X()-sw,
-		vl.p1.Y()-sw,
-		vl.p1.X()+sw,
-		vl.p2.Y()+sw,
-	)
-	return Path{hl, hRect, vl, vRect}
+
+	for i := 1; i < len(points); i++ {
+		line := Line{points[i-1], points[i]}
+
+		if line.IsDegenerate() {
+			panic("Lines cannot be degenerate")
+		}
+		if !line.IsHorizontal() && !line.IsVertical() {
+			panic("Lines must be either horizontal or vertical")
+		}
+		rect := NewRect(
+			min(line.p1.X(), line.p2.X())-sw,
+			min(line.p1.Y(), line.p2.Y())-sw,
+			max(line.p1.X(), line.p2.X())+sw,
+			max(line.p1.Y(), line.p2.Y())+sw,
+		)
+		lines = append(lines, line)
+		rects = append(rects, rect)
+	}
+	return Path{points, lines, rects}
 }
 
 type PathJSON struct {
-	HLineJSON LineJSON
-	VLineJSON LineJSON
+	PointsJSON []PointJSON
 }
 
 func (p *PathJSON) ToPath() *Path {
-	path := NewPath(*p.HLineJSON.ToLine(), *p.VLineJSON.ToLine())
+	var points []Point
+
+	for _, pointJSON := range p.PointsJSON {
+		points = append(points, *pointJSON.ToPoint())
+	}
+	path := NewPath(points)
 	return &path
 }
 
 func NewPathJSON(p *Path) *PathJSON {
+	var pointsJSON []PointJSON
+
+	for i := range p.points {
+		pointsJSON = append(pointsJSON, *NewPointJSON(&p.points[i]))
+	}
 	return &PathJSON{
-		HLineJSON: *NewLineJSON(&p.hLine),
-		VLineJSON: *NewLineJSON(&p.vLine),
+		PointsJSON: pointsJSON,
 	}
 }
 
@@ -90,6 +123,18 @@ type Line struct {
 	p2 Point
 }
 
+func (l *Line) P1() Point {
+	return l.p1
+}
+
+func (l *Line) P2() Point {
+	return l.p2
+}
+
+func (l *Line) Length() int {
+	return Distance(l.p1, l.p2)
+}
+
 func (l *Line) IsDegenerate() bool {
 	return Distance(l.p1, l.p2) == 0
 }
@@ -102,22 +147,6 @@ func (l *Line) IsVertical() bool {
 	return l.p1.X() == l.p2.X()
 }
 
-type LineJSON struct {
-	P1JSON PointJSON
-	P2JSON PointJSON
-}
-
-func (l *LineJSON) ToLine() *Line {
-	line := &Line{
-		p1: *l.P1JSON.ToPoint(),
-		p2: *l.P2JSON.ToPoint(),
-	}
-	return line
-}
-
-func NewLineJSON(l *Line) *LineJSON {
-	return &LineJSON{
-		P1JSON: *NewPointJSON(&l.p1),
-		P2JSON: *NewPointJSON(&l.p2),
-	}
+func NewLine(p1 Point, p2 Point) Line {
+	return Line{p1, p2}
 }
@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPathCanMoveTowards(t *testing.T) {
+	// Z-shaped path
+	path := NewPath([]Point{
+		NewPoint(100, 100),
+		NewPoint(300, 100),
+		NewPoint(300, 200),
+		NewPoint(500, 200),
+	})
+	corner := NewRect(284, 83, 316, 115)
+	middle := NewRect(285, 140, 317, 172)
+
+	if len(path.Lines()) != 3 {
+		t.Fatal("FAILED path must have 3 segments")
+	}
+	if path.Length() != 500 {
+		t.Fatal("FAILED path length must be 500")
+	}
+	if !path.CanMoveTowards(Movement{MoveDirBottom, 1}, &corner) {
+		t.Fatal("FAILED must move down from the corner")
+	}
+	if path.CanMoveTowards(Movement{MoveDirTop, 1}, &corner) {
+		t.Fatal("FAILED must not move up from the corner")
+	}
+	if path.CanMoveTowards(Movement{MoveDirRight, 1}, &middle) {
+		t.Fatal("FAILED must not move right from the vertical segment")
+	}
+}
+
+func TestPathJSON(t *testing.T) {
+	path := NewPath([]Point{
+		NewPoint(100, 100),
+		NewPoint(100, 300),
+		NewPoint(400, 300),
+	})
+
+	if !reflect.DeepEqual(NewPathJSON(&path).ToPath(), &path) {
+		t.Fatal("FAILED path JSON round trip")
+	}
+}
@@ -20,6 +20,10 @@ type Runner struct {
 	count          int
 	currentDungeon *Dungeon
 	currentPaths   []*Path
+	stun           int
+	effects        [PickupKinds]int
+	hazard         *Hazard
+	hazardFrames   int
 }
 
 func (r *Runner) IsOutSide() bool {
@@ -47,10 +51,59 @@ func (r *Runner) SetDungeon(value *Dungeon) {
 
 func (r *Runner) Update() {
 	r.count++
-	r.move()
+
+	if r.stun > 0 {
+		r.stun--
+	} else {
+		r.move()
+	}
+	r.updateHazard()
+
+	for kind := range r.effects {
+		if r.effects[kind] > 0 {
+			r.effects[kind]--
+		}
+	}
 	r.inputs = r.inputs[:0]
 }
 
+// Stun keeps the runner from moving for the given number of frames, unless
+// it has a shield.
+func (r *Runner) Stun(frames int) {
+	if r.HasEffect(PickupShield) {
+		return
+	}
+	r.stun = max(r.stun, frames)
+}
+
+// ApplyEffect gives the runner the effect of the given kind of pickup for the
+// given number of frames.
+func (r *Runner) ApplyEffect(kind PickupKind, frames int) {
+	r.effects[kind] = max(r.effects[kind], frames)
+}
+
+func (r *Runner) RemoveEffect(kind PickupKind) {
+	r.effects[kind] = 0
+}
+
+func (r *Runner) HasEffect(kind PickupKind) bool {
+	return r.effects[kind] > 0
+}
+
+// EffectFrames returns the number of frames left of the given effect.
+func (r *Runner) EffectFrames(kind PickupKind) int {
+	return r.effects[kind]
+}
+
+func (r *Runner) IsStunned() bool {
+	return r.stun > 0
+}
+
+// Collides tells whether the runner bumps into the given one.
+func (r *Runner) Collides(runner *Runner) bool {
+	return r.Rect.Intersects(&runner.Rect)
+}
+
 func (r *Runner) Center() {
 	x := int(-(frameWidth*r.Scale)/2) + screenWidth/2
 	y := int(-(frameHeight*r.Scale)/2) + screenHeight/2
@@ -62,17 +115,76 @@ func (r *Runner) move() {
 		return
 	}
 
+	// Slow zones let the runner walk only every other frame
+	if r.hazard != nil && r.hazard.Kind() == HazardSlow && r.count%2 == 0 {
+		return
+	}
+	steps := 1
+
+	if r.HasEffect(PickupSpeed) {
+		steps = SpeedBoost
+	}
 	for _, direction := range r.inputs {
-		r.moveTowards(direction)
+		for i := 0; i < steps; i++ {
+			r.moveTowards(direction)
+		}
 	}
 }
 
-func (r *Runner) moveTowards(direction int) {
+// updateHazard applies the hazard the runner stands on, spikes and teleport
+// pads trigger once as the runner steps on them.
+func (r *Runner) updateHazard() {
+	var hazard *Hazard
+
+	if r.isInsideDungeon() {
+		hazard = r.currentDungeon.HazardAt(&r.Rect)
+	}
+	entered := hazard != r.hazard
+
+	if entered {
+		r.hazardFrames = 0
+	}
+	r.hazard = hazard
+
+	if hazard == nil {
+		return
+	}
+	r.hazardFrames++
+
+	switch hazard.Kind() {
+	case HazardSpikes:
+		if entered {
+			r.Stun(SpikeStunFrames)
+		}
+	case HazardTeleport:
+		if entered {
+			target := hazard.Target()
+			r.setPosition(target.X(), target.Y())
+			r.hazard = nil
+		}
+	case HazardCollapse:
+		if r.hazardFrames >= CollapseFrames {
+			r.SetDungeon(r.currentDungeon)
+			r.Stun(FallStunFrames)
+			r.hazard = nil
+		}
+	}
+}
+
+// CanMoveTowards tells whether the runner can walk one step towards the given
+// direction. Within a dungeon only its walls block the runner, so it leaves
+// through the doors, otherwise it must keep within its current paths.
+func (r *Runner) CanMoveTowards(direction int) bool {
 	movement := Movement{direction, 1}
-	canMoveInsideDungeon := r.canMoveInsideDungeonTowards(movement)
-	canMoveInsidePaths := r.canMoveInsidePathsTowards(movement)
 
-	if !canMoveInsideDungeon && !canMoveInsidePaths {
+	if r.isInsideDungeon() {
+		return r.canMoveInsideDungeonTowards(movement)
+	}
+	return r.canMoveInsidePathsTowards(movement)
+}
+
+func (r *Runner) moveTowards(direction int) {
+	if !r.CanMoveTowards(direction) {
 		return
 	}
 
@@ -123,12 +235,31 @@ func (r *Runner) isInsideDungeon() bool {
 	return r.currentDungeon != nil
 }
 
+func (r *Runner) SetPosition(x int, y int) {
+	r.setPosition(x, y)
+}
+
 func (r *Runner) setPosition(x int, y int) {
 	r.Rect.setPosition(x, y)
 }
 
+// CheckDiamondCollision tells whether the runner picks the diamond up, from
+// further away if it has a magnet.
 func (r *Runner) CheckDiamondCollision(diamond *Diamond) bool {
-	return diamond.Collides(&r.Rect)
+	if !r.HasEffect(PickupMagnet) {
+		return diamond.Collides(&r.Rect)
+	}
+	rect := Rect{
+		left:   max(0, r.Rect.Left()-MagnetRadiusPx),
+		top:    max(0, r.Rect.Top()-MagnetRadiusPx),
+		right:  r.Rect.Right() + MagnetRadiusPx,
+		bottom: r.Rect.Bottom() + MagnetRadiusPx,
+	}
+	return diamond.Collides(&rect)
+}
+
+func (r *Runner) CheckPickupCollision(pickup *Pickup) bool {
+	return pickup.Collides(&r.Rect)
 }
 
 func NewRunner() Runner {
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2021 Tobias Briones. All rights reserved.
+ */
+
+package model
+
+import "testing"
+
+func TestRunnerStun(t *testing.T) {
+	dungeon := NewDungeon(NewPoint(100, 100), DimensionFactor{Width: 4, Height: 4})
+	runner := NewRunner()
+	runner.SetDungeon(&dungeon)
+	left := runner.Rect.Left()
+
+	runner.Stun(2)
+
+	for i := 0; i < 2; i++ {
+		runner.PushInput(MoveDirRight)
+		runner.Update()
+	}
+	if runner.IsStunned() || runner.Rect.Left() != left {
+		t.Fatal("FAILED stunned runner must not move")
+	}
+
+	runner.PushInput(MoveDirRight)
+	runner.Update()
+
+	if runner.Rect.Left() != left+1 {
+		t.Fatal("FAILED runner must move once the stun is over")
+	}
+
+	other := NewRunner()
+	other.SetDungeon(&dungeon)
+
+	if !runner.Collides(&other) {
+		t.Fatal("FAILED runners in the same spot must collide")
+	}
+}
+
+func TestRunnerHazards(t *testing.T) {
+	dungeon := NewDungeon(NewPoint(100, 100), DimensionFactor{Width: 4, Height: 4})
+	runner := NewRunner()
+	runner.SetDungeon(&dungeon)
+	center := runner.Rect.Center()
+
+	dungeon.AddHazard(NewHazard(NewPoint(center.X()+1, center.Y()-24), HazardSpikes, Point{}))
+	runner.PushInput(MoveDirRight)
+	runner.Update()
+
+	if !runner.IsStunned() {
+		t.Fatal("FAILED runner stepping on spikes must be stunned")
+	}
+
+	other := NewDungeon(NewPoint(100, 100), DimensionFactor{Width: 4, Height: 4})
+	target := NewPoint(120, 120)
+	runner = NewRunner()
+	runner.SetDungeon(&other)
+
+	other.AddHazard(NewHazard(NewPoint(center.X()+1, center.Y()-24), HazardTeleport, target))
+	runner.PushInput(MoveDirRight)
+	runner.Update()
+
+	if runner.Rect.Left() != target.X() || runner.Rect.Top() != target.Y() {
+		t.Fatal("FAILED teleport pad must send the runner to its target")
+	}
+}
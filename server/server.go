@@ -5,34 +5,90 @@
 package main
 
 import (
+	"flag"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"server/ai"
+	"sync/atomic"
+	"time"
 )
 
 const (
 	addr = "localhost:8080"
 )
 
-var globalId = -1
+// globalId is the last id given to a player or bot, see nextId.
+var globalId int64 = -1
+
+var (
+	seed   = flag.Int64("seed", 0, "seed to generate every match from, random if 0")
+	width  = flag.Int("width", ai.DefaultDungeonConfig.Width, "world width")
+	height = flag.Int("height", ai.DefaultDungeonConfig.Height, "world height")
+	rooms  = flag.Int("rooms", ai.DefaultDungeonConfig.Rooms, "number of dungeons, as many as fit if 0")
+	layout = flag.String("layout", ai.DefaultDungeonConfig.Layout, "dungeon layout: rejection, bsp, poisson or separation")
+
+	diamonds  = flag.String("diamonds", ai.DefaultDiamondConfig.Placement, "diamond placement: uniform, size, leaves, far, treasure or corridor")
+	count     = flag.Int("count", ai.DefaultDiamondConfig.Count, "number of diamonds, one per dungeon if 0")
+	tolerance = flag.Float64("tolerance", ai.DefaultDiamondConfig.Tolerance, "fraction over their even share of diamonds a spawn can be closest to, unbounded if 0")
+
+	respawn  = flag.String("respawn", ai.DefaultSpawnConfig.Mode, "diamond respawn: none, wave or cooldown")
+	interval = flag.Duration("interval", ai.DefaultSpawnConfig.Interval, "time between waves, or the cooldown of each diamond")
+
+	hazards = flag.Int("hazards", ai.DefaultHazardConfig.Max, "most hazards in each dungeon, none if 0")
+
+	collision = flag.Bool("collision", false, "runners block each other, and bumping stuns")
+	stun      = flag.Duration("stun", 2*time.Second, "time a bumped runner is stunned")
+
+	bots          = flag.Int("bots", 0, "number of bots playing along")
+	botDifficulty = flag.String("bot-difficulty", ai.NormalBot, "bot difficulty: easy, normal or hard")
+)
 
 func main() {
+	flag.Parse()
+
+	config := ai.DefaultMatchConfig
+	config.Dungeons.Width = *width
+	config.Dungeons.Height = *height
+	config.Dungeons.Rooms = *rooms
+	config.Dungeons.Layout = *layout
+	config.Diamonds.Placement = *diamonds
+	config.Diamonds.Count = *count
+	config.Diamonds.Tolerance = *tolerance
+	config.Spawns.Mode = *respawn
+	config.Spawns.Interval = *interval
+	config.Hazards.Max = *hazards
+
+	if err := config.Validate(); err != nil {
+		log.Fatal("Invalid match config: " + err.Error())
+	}
+	botConfig, err := ai.GetBotConfig(*botDifficulty)
+
+	if err != nil {
+		log.Fatal("Invalid bot config: " + err.Error())
+	}
+
 	gin.DefaultWriter = ioutil.Discard
 	r := gin.Default()
 
 	dataCh := make(chan *ResponseData)
 	quitCh := make(chan struct{})
-	hub := NewHub(dataCh, quitCh)
+	rules := Rules{
+		Collision: *collision,
+		Stun:      *stun,
+	}
+	hub := NewHub(dataCh, quitCh, *seed, config, rules)
 
 	defer close(quitCh)
 	go hub.Start()
 
-	r.GET("/", wsHandler(getUpgrader(), quitCh, hub))
-	err := r.Run(addr)
+	addBots(hub, *bots, botConfig)
 
-	if err != nil {
+	r.GET("/", wsHandler(getUpgrader(), quitCh, hub))
+	r.GET("/status", statusHandler(hub))
+	if err := r.Run(addr); err != nil {
 		log.Fatal("Unable to run server: " + err.Error())
 	}
 }
@@ -59,6 +115,12 @@ func wsHandler(updgrader *websocket.Upgrader, quit chan struct{}, hub *Hub) gin.
 	}
 }
 
+func statusHandler(hub *Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, hub.Status())
+	}
+}
+
 func getUpgrader() *websocket.Upgrader {
 	return &websocket.Upgrader{
 		ReadBufferSize:  1024,
@@ -69,11 +131,17 @@ func getUpgrader() *websocket.Upgrader {
 
 func waitForConfirm(conn *websocket.Conn) (int, string) {
 	_, p, err := conn.ReadMessage()
-	globalId++
+	id := nextId()
 
 	if err != nil {
 		log.Println(err)
-		return globalId, ""
+		return id, ""
 	}
-	return globalId, string(p)
+	return id, string(p)
+}
+
+// nextId returns a new id for a player or bot, handlers and bots take them
+// concurrently.
+func nextId() int {
+	return int(atomic.AddInt64(&globalId, 1))
 }